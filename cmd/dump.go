@@ -5,7 +5,6 @@ import (
 
 	"github.com/flashbots/gh-artifacts-sync/config"
 	"github.com/urfave/cli/v2"
-	"gopkg.in/yaml.v2"
 )
 
 func CommandDump(cfg *config.Config) *cli.Command {
@@ -15,7 +14,7 @@ func CommandDump(cfg *config.Config) *cli.Command {
 	cmd.Usage = "dump the effective configuration"
 
 	cmd.Action = func(_ *cli.Context) error {
-		bytes, err := yaml.Marshal(cfg)
+		bytes, err := cfg.MarshalYamlRedacted()
 		if err != nil {
 			return err
 		}
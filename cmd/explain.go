@@ -0,0 +1,385 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	categoryExplain = "explain"
+)
+
+var (
+	errExplainUnknownRepository = errors.New("repository is not configured")
+	errExplainNothingToExplain  = errors.New("nothing to explain (specify release/asset, workflow/artifact, container, maven, or npm)")
+)
+
+func CommandExplain(cfg *config.Config) *cli.Command {
+	var (
+		repo, release, releaseTag, asset                   string
+		workflow, artifact, headBranch, headSHA            string
+		actor, event, headRepo                             string
+		container, tag, digest, maven, npm, packageVersion string
+		runNumber                                          int
+		draft, prerelease                                  bool
+	)
+
+	cmd := &cli.Command{
+		Name:  "explain",
+		Usage: "explain which rules match an event and where it would be synchronised to",
+
+		Before: func(_ *cli.Context) error {
+			return cfg.ValidateRepositories()
+		},
+	}
+
+	cmd.Flags = append(cmd.Flags, // --xxx
+		&cli.StringFlag{ // --repo
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &repo,
+			Name:        "repo",
+			Required:    true,
+			Usage:       "`owner/name` of the repository",
+		},
+
+		&cli.StringFlag{ // --release
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &release,
+			Name:        "release",
+			Usage:       "release `name`",
+		},
+
+		&cli.StringFlag{ // --release-tag
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &releaseTag,
+			Name:        "release-tag",
+			Usage:       "release `tag` (defaults to release name)",
+		},
+
+		&cli.BoolFlag{ // --draft
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &draft,
+			Name:        "draft",
+			Usage:       "the release is a draft",
+		},
+
+		&cli.BoolFlag{ // --prerelease
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &prerelease,
+			Name:        "prerelease",
+			Usage:       "the release is a pre-release",
+		},
+
+		&cli.StringFlag{ // --asset
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &asset,
+			Name:        "asset",
+			Usage:       "release asset `name`",
+		},
+
+		&cli.StringFlag{ // --workflow
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &workflow,
+			Name:        "workflow",
+			Usage:       "workflow `file` (e.g. release.yaml)",
+		},
+
+		&cli.StringFlag{ // --artifact
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &artifact,
+			Name:        "artifact",
+			Usage:       "workflow artifact `name`",
+		},
+
+		&cli.StringFlag{ // --head-branch
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &headBranch,
+			Name:        "head-branch",
+			Usage:       "head `branch` of the workflow run",
+		},
+
+		&cli.StringFlag{ // --head-sha
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &headSHA,
+			Name:        "head-sha",
+			Usage:       "head commit `sha` of the workflow run",
+		},
+
+		&cli.StringFlag{ // --head-repo
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &headRepo,
+			Name:        "head-repo",
+			Usage:       "`owner/name` of the head repository of the workflow run (if it's from a fork)",
+		},
+
+		&cli.StringFlag{ // --actor
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &actor,
+			Name:        "actor",
+			Usage:       "`login` of the user that triggered the workflow run",
+		},
+
+		&cli.StringFlag{ // --event
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &event,
+			Name:        "event",
+			Usage:       "`event` that triggered the workflow run (e.g. push)",
+		},
+
+		&cli.IntFlag{ // --run-number
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &runNumber,
+			Name:        "run-number",
+			Usage:       "workflow run `number`",
+		},
+
+		&cli.StringFlag{ // --container
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &container,
+			Name:        "container",
+			Usage:       "container package `name`",
+		},
+
+		&cli.StringFlag{ // --tag
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &tag,
+			Name:        "tag",
+			Usage:       "container `tag`",
+		},
+
+		&cli.StringFlag{ // --digest
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &digest,
+			Name:        "digest",
+			Usage:       "container `digest`",
+		},
+
+		&cli.StringFlag{ // --maven
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &maven,
+			Name:        "maven",
+			Usage:       "maven package `name`",
+		},
+
+		&cli.StringFlag{ // --npm
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &npm,
+			Name:        "npm",
+			Usage:       "npm package `name`",
+		},
+
+		&cli.StringFlag{ // --package-version
+			Category:    strings.ToUpper(categoryExplain),
+			Destination: &packageVersion,
+			Name:        "package-version",
+			Usage:       "maven or npm package `version`",
+		},
+	)
+
+	cmd.Action = func(_ *cli.Context) error {
+		r, isConfigured := cfg.Repositories[repo]
+		if !isConfigured {
+			return fmt.Errorf("%w: %s", errExplainUnknownRepository, repo)
+		}
+
+		owner, name, _ := strings.Cut(repo, "/")
+		data := &config.TemplateData{
+			Repo:      name,
+			RepoOwner: owner,
+		}
+
+		explained := false
+
+		if release != "" || asset != "" {
+			explained = true
+			data := *data
+			data.ReleaseName = release
+			data.ReleaseTag = releaseTag
+			if data.ReleaseTag == "" {
+				data.ReleaseTag = release
+			}
+
+			fmt.Printf("release %q, asset %q:\n", release, asset)
+			matches, err := r.MatchReleaseAsset(&data, asset)
+			if err != nil {
+				fmt.Printf("  error: %s\n", err)
+			}
+			if len(matches) == 0 {
+				fmt.Printf("  no matching rules\n")
+			}
+			for _, m := range matches {
+				if !m.Release.Accepts(draft, prerelease) {
+					fmt.Printf("  rule: releases[%q].assets[%q] (drafts or pre-releases are not accepted)\n", m.ReleaseRegexp, m.AssetRegexp)
+					continue
+				}
+				fmt.Printf("  rule: releases[%q].assets[%q]\n", m.ReleaseRegexp, m.AssetRegexp)
+				explainVersion(m.Data)
+				explainDestinations(m.Asset.Destinations, m.Data)
+			}
+		}
+
+		if workflow != "" || artifact != "" {
+			explained = true
+			data := *data
+			data.HeadBranch = headBranch
+			data.HeadSHA = headSHA
+			data.RunNumber = runNumber
+
+			fmt.Printf("workflow %q, artifact %q:\n", workflow, artifact)
+			w, isConfigured := r.Workflows[workflow]
+			if !isConfigured {
+				fmt.Printf("  no matching rules (workflow is not configured)\n")
+			} else {
+				matches, err := w.MatchArtifact(&data, artifact)
+				if err != nil {
+					fmt.Printf("  error: %s\n", err)
+				}
+				if len(matches) == 0 {
+					fmt.Printf("  no matching rules\n")
+				}
+				fromFork := headRepo != "" && headRepo != repo
+				for _, m := range matches {
+					if reason := w.SkipReason(actor, headBranch, event, fromFork); reason != "" {
+						fmt.Printf("  rule: workflows[%q].artifacts[%q] (%s)\n", workflow, m.ArtifactRegexp, reason)
+						continue
+					}
+					fmt.Printf("  rule: workflows[%q].artifacts[%q]\n", workflow, m.ArtifactRegexp)
+					explainVersion(m.Data)
+					explainDestinations(m.Artifact.Destinations, m.Data)
+				}
+			}
+		}
+
+		if container != "" {
+			explained = true
+			data := *data
+			data.Digest = digest
+			data.Package = container
+			data.Tag = tag
+			data.Version = tag
+
+			fmt.Printf("container %q, tag %q:\n", container, tag)
+			c, isConfigured := r.Containers[container]
+			switch {
+			case !isConfigured:
+				fmt.Printf("  no matching rules\n")
+			case tag != "" && !c.HasTag(tag):
+				fmt.Printf("  rule: containers[%q] (tag is filtered out)\n", container)
+			default:
+				fmt.Printf("  rule: containers[%q]\n", container)
+				if tag != "" {
+					explainVersion(&data)
+				} else {
+					fmt.Printf("  version: <none> (tag-less)\n")
+				}
+				explainDestinations(c.Destinations, &data)
+			}
+		}
+
+		for _, p := range []struct{ ecosystem, pkg string }{
+			{"maven", maven},
+			{"npm", npm},
+		} {
+			ecosystem, pkg := p.ecosystem, p.pkg
+			if pkg == "" {
+				continue
+			}
+			explained = true
+			data := *data
+			data.Package = pkg
+			data.Version = packageVersion
+
+			fmt.Printf("%s package %q, version %q:\n", ecosystem, pkg, packageVersion)
+			var destinations []*config.Destination
+			if ecosystem == "maven" {
+				if m, isConfigured := r.Maven[pkg]; isConfigured {
+					destinations = m.Destinations
+				}
+			} else {
+				if n, isConfigured := r.Npm[pkg]; isConfigured {
+					destinations = n.Destinations
+				}
+			}
+			if destinations == nil {
+				fmt.Printf("  no matching rules\n")
+				continue
+			}
+			fmt.Printf("  rule: %s[%q]\n", ecosystem, pkg)
+			explainDestinations(destinations, &data)
+		}
+
+		if !explained {
+			return errExplainNothingToExplain
+		}
+
+		if r.Remote != nil {
+			fmt.Printf("\nnote: rules from per-repository config (%s) are not considered\n", r.Remote.Path)
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+func explainVersion(data *config.TemplateData) {
+	fmt.Printf("  version: %s\n", data.Version)
+	if len(data.Groups) > 0 {
+		groups := make([]string, 0, len(data.Groups))
+		for _, name := range slices.Sorted(maps.Keys(data.Groups)) {
+			groups = append(groups, fmt.Sprintf("%s=%q", name, data.Groups[name]))
+		}
+		fmt.Printf("  groups: %s\n", strings.Join(groups, ", "))
+	}
+}
+
+func explainDestinations(destinations []*config.Destination, data *config.TemplateData) {
+	for _, dst := range destinations {
+		_dst, err := dst.Render(data)
+		if err != nil {
+			fmt.Printf("  - %s: error: %s\n", dst.Type, err)
+			continue
+		}
+
+		switch _dst.Type {
+		case config.DestinationGcpArtifactRegistryDocker:
+			ref := _dst.Path + "/" + _dst.Package
+			switch {
+			case data.Tag != "":
+				ref += ":" + data.Tag
+			case _dst.Tagless == config.DestinationTaglessTag && data.Digest != "":
+				ref += ":" + strings.ReplaceAll(data.Digest, ":", "-")
+			case _dst.Tagless == config.DestinationTaglessTag:
+				ref += ":sha256-<digest>"
+			case _dst.Tagless == config.DestinationTaglessDigest:
+				ref += "@<digest of the re-assembled image>"
+			default:
+				fmt.Printf("  - %s: %s (not synchronised: tag-less version, see `tagless`)\n", _dst.Type, ref)
+				continue
+			}
+			fmt.Printf("  - %s: %s\n", _dst.Type, ref)
+
+		case config.DestinationGcpArtifactRegistryGeneric:
+			version := _dst.Version
+			if version == "" {
+				version = data.Version
+			}
+			filename := "<file name in the archive>"
+			if _dst.Filename != "" {
+				filename = _dst.Filename + " (template)"
+			}
+			fmt.Printf("  - %s: %s/packages/%s/versions/%s (files: %s)\n",
+				_dst.Type, _dst.Path, _dst.Package, version, filename,
+			)
+
+		default:
+			fmt.Printf("  - %s: %s/%s@%s\n", _dst.Type, _dst.Path, _dst.Package, data.Version)
+		}
+	}
+}
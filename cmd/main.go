@@ -23,10 +23,18 @@ const (
 var (
 	flagConfig = &cli.StringFlag{
 		Name:  "config",
-		Usage: "`path` to the configuration file",
+		Usage: "`path` to the configuration file, directory (all .yaml and .yml files in it), or glob",
 	}
 )
 
+// configInputSource feeds the cli flags from the (already loaded and expanded)
+// configuration file.
+func configInputSource(cfg *config.Config) func(*cli.Context) (altsrc.InputSourceContext, error) {
+	return func(clictx *cli.Context) (altsrc.InputSourceContext, error) {
+		return altsrc.NewMapInputSource(clictx.String(flagConfig.Name), cfg.Source()), nil
+	}
+}
+
 func main() {
 	cfg := config.New()
 
@@ -55,6 +63,10 @@ func main() {
 	commands := []*cli.Command{
 		CommandServe(cfg),
 		CommandDump(cfg),
+		CommandExplain(cfg),
+		CommandReplay(cfg),
+		CommandSchema(cfg),
+		CommandValidate(cfg),
 		CommandHelp(cfg),
 	}
 
@@ -77,7 +89,7 @@ func main() {
 
 				if err := altsrc.InitInputSourceWithContext(
 					flags,
-					altsrc.NewYamlSourceFromFlagFunc(flagConfig.Name),
+					configInputSource(cfg),
 				)(clictx); err != nil {
 					return err
 				}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/metrics"
+	"github.com/flashbots/gh-artifacts-sync/server"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	categoryReplay = "replay"
+)
+
+var (
+	errReplayNothingToReplay     = errors.New("nothing to replay (specify either delivery or file)")
+	errReplayDeliveryAndFile     = errors.New("only one of delivery or file can be specified")
+	errReplayNoDeliveriesArchive = errors.New("deliveries archive is not configured (see --dir-deliveries)")
+)
+
+func CommandReplay(cfg *config.Config) *cli.Command {
+	var (
+		delivery, file, event string
+	)
+
+	cmd := CommandServe(cfg)
+
+	cmd.Name = "replay"
+	cmd.Usage = "re-process archived webhook delivery (or a json payload) and persist the resulting jobs"
+
+	cmd.Flags = append(cmd.Flags, // --xxx
+		&cli.StringFlag{ // --delivery
+			Category:    strings.ToUpper(categoryReplay),
+			Destination: &delivery,
+			Name:        "delivery",
+			Usage:       "`id` of the delivery in the deliveries archive",
+		},
+
+		&cli.StringFlag{ // --file
+			Category:    strings.ToUpper(categoryReplay),
+			Destination: &file,
+			Name:        "file",
+			Usage:       "`path` to the archived delivery or to the json payload of the webhook",
+		},
+
+		&cli.StringFlag{ // --event
+			Category:    strings.ToUpper(categoryReplay),
+			Destination: &event,
+			Name:        "event",
+			Usage:       "event `type` (e.g. release, registry_package, workflow_run), required for json payloads",
+		},
+	)
+
+	cmd.Action = func(_ *cli.Context) error {
+		switch {
+		case delivery == "" && file == "":
+			return errReplayNothingToReplay
+		case delivery != "" && file != "":
+			return errReplayDeliveryAndFile
+		case delivery != "":
+			if cfg.Dir.Deliveries == "" {
+				return errReplayNoDeliveriesArchive
+			}
+			file = filepath.Join(cfg.Dir.Deliveries, filepath.Base(delivery)+".json")
+		}
+
+		d, err := server.LoadDelivery(file, event)
+		if err != nil {
+			return err
+		}
+
+		if err := metrics.Setup(context.Background()); err != nil {
+			return fmt.Errorf("failed to setup metrics: %w", err)
+		}
+
+		s, err := server.New(cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx := logutils.ContextWithLogger(context.Background(), zap.L())
+		if err := s.Replay(ctx, d); err != nil {
+			return err
+		}
+
+		fmt.Printf("Replayed delivery %s (%s)\n", d.ID, d.Event)
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/urfave/cli/v2"
+)
+
+func CommandSchema(_ *config.Config) *cli.Command {
+	var repository bool
+
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "print json schema of the configuration file",
+
+		Flags: []cli.Flag{
+			&cli.BoolFlag{ // --repository
+				Destination: &repository,
+				Name:        "repository",
+				Usage:       "print json schema of the per-repository configuration file instead",
+			},
+		},
+
+		Action: func(_ *cli.Context) error {
+			schema := config.Schema()
+			if repository {
+				schema = config.RepositorySchema()
+			}
+
+			bytes, err := json.MarshalIndent(schema, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(bytes))
+			return nil
+		},
+	}
+}
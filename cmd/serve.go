@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
 
 	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/metrics"
 	"github.com/flashbots/gh-artifacts-sync/server"
 )
 
@@ -26,15 +29,33 @@ var (
 	)
 
 	errGithubWebhookSecretCollision = fmt.Errorf(
-		"cannot specify both '-%s-webhook-secret' and '-%s-webhook-secret-path'",
-		categoryGithub, categoryGithub,
+		"cannot specify both '-%s-webhook-secret' (or '-%s-webhook-secrets') and '-%s-webhook-secret-path'",
+		categoryGithub, categoryGithub, categoryGithub,
 	)
 )
 
-func CommandServe(cfg *config.Config) *cli.Command {
-	var githubAppPrivateKeyPath, githubWebhookSecretPath string
+// serveOptions are the values of the serve flags that are not stored into the
+// config directly.
+type serveOptions struct {
+	githubAppPrivateKeyPath  string
+	githubWebhookSecretPaths *cli.StringSlice
+	githubWebhookSecrets     *cli.StringSlice
+}
 
+// serveFlags returns the flags of the server (they are shared with the
+// commands that need to see the same settings as serve, e.g. validate).
+func serveFlags(cfg *config.Config, opts *serveOptions) []cli.Flag {
 	dirFlags := []cli.Flag{ // --dir-xxx
+		&cli.StringFlag{ // --dir-deliveries
+			Aliases:     []string{"dir.deliveries"},
+			Category:    strings.ToUpper(categoryDir),
+			Destination: &cfg.Dir.Deliveries,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryDir) + "_DELIVERIES"},
+			Name:        categoryDir + "-deliveries",
+			Usage:       "a `path` to the directory where raw webhook deliveries will be archived (disabled if empty)",
+			Value:       "",
+		},
+
 		&cli.StringFlag{ // --dir-downloads
 			Aliases:     []string{"dir.downloads"},
 			Category:    strings.ToUpper(categoryDir),
@@ -55,6 +76,16 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Value:       "./jobs",
 		},
 
+		&cli.StringFlag{ // --dir-state
+			Aliases:     []string{"dir.state"},
+			Category:    strings.ToUpper(categoryDir),
+			Destination: &cfg.Dir.State,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryDir) + "_STATE"},
+			Name:        categoryDir + "-state",
+			Usage:       "a `path` to the directory where the state (e.g. ids of processed webhook deliveries) will be persisted",
+			Value:       "./state",
+		},
+
 		&cli.StringFlag{ // --dir-soft-delete-downloads
 			Aliases:     []string{"dir.soft_delete_downloads"},
 			Category:    strings.ToUpper(categoryDir),
@@ -92,7 +123,46 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Destination: &cfg.Github.App.InstallationID,
 			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_INSTALLATION_ID"},
 			Name:        categoryGithub + "-installation-id",
-			Usage:       "installation `id` of the github app",
+			Usage:       "installation `id` of the github app (if omitted, resolved per event or repository)",
+		}),
+
+		altsrc.NewStringFlag(&cli.StringFlag{ // --github-base-url
+			Aliases:     []string{"github.base_url"},
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: &cfg.Github.BaseURL,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_BASE_URL"},
+			Name:        categoryGithub + "-base-url",
+			Usage:       "base `url` of github enterprise server api (empty for github.com)",
+			Value:       "",
+		}),
+
+		altsrc.NewDurationFlag(&cli.DurationFlag{ // --github-catch-up-interval
+			Aliases:     []string{"github.catch_up_interval"},
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: &cfg.Github.CatchUpInterval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_CATCH_UP_INTERVAL"},
+			Name:        categoryGithub + "-catch-up-interval",
+			Usage:       "`interval` at which to look for missed webhook deliveries of the github app, and process them (0 to disable)",
+			Value:       0,
+		}),
+
+		altsrc.NewBoolFlag(&cli.BoolFlag{ // --github-disable-webhook
+			Aliases:     []string{"github.disable_webhook"},
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: &cfg.Github.DisableWebhook,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_DISABLE_WEBHOOK"},
+			Name:        categoryGithub + "-disable-webhook",
+			Usage:       "do not serve github webhook (e.g. when github can not reach the server and polling is used instead)",
+		}),
+
+		altsrc.NewDurationFlag(&cli.DurationFlag{ // --github-poll-interval
+			Aliases:     []string{"github.poll_interval"},
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: &cfg.Github.PollInterval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_POLL_INTERVAL"},
+			Name:        categoryGithub + "-poll-interval",
+			Usage:       "`interval` at which to poll github api for new releases, workflow runs, and container versions (0 to disable)",
+			Value:       0,
 		}),
 
 		altsrc.NewStringFlag(&cli.StringFlag{ // --github-private-key
@@ -106,12 +176,32 @@ func CommandServe(cfg *config.Config) *cli.Command {
 
 		&cli.StringFlag{ // --github-private-key-path
 			Category:    strings.ToUpper(categoryGithub),
-			Destination: &githubAppPrivateKeyPath,
+			Destination: &opts.githubAppPrivateKeyPath,
 			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_PRIVATE_KEY_PATH"},
 			Name:        categoryGithub + "-private-key-path",
 			Usage:       "`path` to a .pem file with private `key` of the github app",
 		},
 
+		altsrc.NewStringFlag(&cli.StringFlag{ // --github-registry-host
+			Aliases:     []string{"github.registry_host"},
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: &cfg.Github.RegistryHost,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_REGISTRY_HOST"},
+			Name:        categoryGithub + "-registry-host",
+			Usage:       "`host` of github container registry",
+			Value:       "ghcr.io",
+		}),
+
+		altsrc.NewStringFlag(&cli.StringFlag{ // --github-upload-url
+			Aliases:     []string{"github.upload_url"},
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: &cfg.Github.UploadURL,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_UPLOAD_URL"},
+			Name:        categoryGithub + "-upload-url",
+			Usage:       "upload `url` of github enterprise server api (defaults to the base url)",
+			Value:       "",
+		}),
+
 		altsrc.NewStringFlag(&cli.StringFlag{ // --github-webhook-secret
 			Aliases:     []string{"github.webhook_secret"},
 			Category:    strings.ToUpper(categoryGithub),
@@ -121,16 +211,45 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Usage:       "secret `token` for the github webhook",
 		}),
 
-		&cli.StringFlag{ // --github-webhook-secret-path
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{ // --github-webhook-secrets
+			Aliases:     []string{"github.webhook_secrets"},
 			Category:    strings.ToUpper(categoryGithub),
-			Destination: &githubWebhookSecretPath,
+			Destination: opts.githubWebhookSecrets,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_WEBHOOK_SECRETS"},
+			Name:        categoryGithub + "-webhook-secrets",
+			Usage:       "secret `tokens` for the github webhook (all are accepted, the first one is primary)",
+		}),
+
+		&cli.StringSliceFlag{ // --github-webhook-secret-path
+			Category:    strings.ToUpper(categoryGithub),
+			Destination: opts.githubWebhookSecretPaths,
 			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGithub) + "_WEBHOOK_SECRET_PATH"},
 			Name:        categoryGithub + "-webhook-secret-path",
-			Usage:       "`path` to a file with secret token for the github webhook",
+			Usage:       "`path` to a file with secret token for the github webhook (can be repeated to accept several secrets, the first one is primary)",
 		},
 	}
 
 	serverFlags := []cli.Flag{ // -server-xxx
+		altsrc.NewDurationFlag(&cli.DurationFlag{ // --server-config-watch-interval
+			Aliases:     []string{"server.config_watch_interval"},
+			Category:    strings.ToUpper(categoryServer),
+			Destination: &cfg.Server.ConfigWatchInterval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryServer) + "_CONFIG_WATCH_INTERVAL"},
+			Name:        categoryServer + "-config-watch-interval",
+			Usage:       "`interval` at which to check the configuration file for changes and reload it (0 to disable)",
+			Value:       0,
+		}),
+
+		altsrc.NewIntFlag(&cli.IntFlag{ // --server-dedupe-deliveries
+			Aliases:     []string{"server.dedupe_deliveries"},
+			Category:    strings.ToUpper(categoryServer),
+			Destination: &cfg.Server.DedupeDeliveries,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryServer) + "_DEDUPE_DELIVERIES"},
+			Name:        categoryServer + "-dedupe-deliveries",
+			Usage:       "`count` of the most recent webhook delivery ids to remember for deduplication (0 to disable)",
+			Value:       10000,
+		}),
+
 		&cli.StringFlag{ // --server-listen-address
 			Aliases:     []string{"server.listen_address"},
 			Category:    strings.ToUpper(categoryServer),
@@ -140,13 +259,35 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Usage:       "`host:port` for the server to listen on",
 			Value:       "0.0.0.0:8080",
 		},
+
+		altsrc.NewDurationFlag(&cli.DurationFlag{ // --server-retention-interval
+			Aliases:     []string{"server.retention_interval"},
+			Category:    strings.ToUpper(categoryServer),
+			Destination: &cfg.Server.RetentionInterval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryServer) + "_RETENTION_INTERVAL"},
+			Name:        categoryServer + "-retention-interval",
+			Usage:       "`interval` at which to enforce the retention rules of the destinations (0 to disable)",
+			Value:       time.Hour,
+		}),
 	}
 
-	flags := slices.Concat(
+	return slices.Concat(
 		dirFlags,
 		githubFlags,
 		serverFlags,
 	)
+}
+
+func newServeOptions() *serveOptions {
+	return &serveOptions{
+		githubWebhookSecretPaths: cli.NewStringSlice(),
+		githubWebhookSecrets:     cli.NewStringSlice(),
+	}
+}
+
+func CommandServe(cfg *config.Config) *cli.Command {
+	opts := newServeOptions()
+	flags := serveFlags(cfg, opts)
 
 	return &cli.Command{
 		Name:  "serve",
@@ -154,36 +295,44 @@ func CommandServe(cfg *config.Config) *cli.Command {
 		Flags: flags,
 
 		Before: func(clictx *cli.Context) error {
-			if err := altsrc.InitInputSourceWithContext(flags, altsrc.NewYamlSourceFromFlagFunc(flagConfig.Name))(clictx); err != nil {
+			if err := altsrc.InitInputSourceWithContext(flags, configInputSource(cfg))(clictx); err != nil {
 				return err
 			}
 
-			if githubAppPrivateKeyPath != "" {
+			if opts.githubAppPrivateKeyPath != "" {
 				if cfg.Github.App.PrivateKey != "" {
 					return errGithubAppPrivateKeyCollision
 				}
-				bytes, err := os.ReadFile(githubAppPrivateKeyPath)
+				bytes, err := os.ReadFile(opts.githubAppPrivateKeyPath)
 				if err != nil {
 					return err
 				}
 				cfg.Github.App.PrivateKey = string(bytes)
 			}
 
-			if githubWebhookSecretPath != "" {
-				if cfg.Github.WebhookSecret != "" {
+			cfg.Github.WebhookSecrets = opts.githubWebhookSecrets.Value()
+
+			if paths := opts.githubWebhookSecretPaths.Value(); len(paths) > 0 {
+				if len(cfg.Github.AcceptedWebhookSecrets()) > 0 {
 					return errGithubWebhookSecretCollision
 				}
-				bytes, err := os.ReadFile(githubWebhookSecretPath)
-				if err != nil {
-					return err
+				for _, path := range paths {
+					bytes, err := os.ReadFile(path)
+					if err != nil {
+						return err
+					}
+					cfg.Github.WebhookSecrets = append(cfg.Github.WebhookSecrets, string(bytes))
 				}
-				cfg.Github.WebhookSecret = string(bytes)
 			}
 
 			return cfg.Validate()
 		},
 
 		Action: func(_ *cli.Context) error {
+			if err := metrics.Setup(context.Background()); err != nil {
+				return fmt.Errorf("failed to setup metrics: %w", err)
+			}
+
 			s, err := server.New(cfg)
 			if err != nil {
 				return err
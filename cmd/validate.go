@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
+)
+
+var (
+	errValidateNoConfig = errors.New("must provide configuration file (see --config)")
+)
+
+func CommandValidate(cfg *config.Config) *cli.Command {
+	opts := newServeOptions()
+	flags := serveFlags(cfg, opts)
+
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "validate the configuration (exits with non-zero code if it's invalid)",
+		Flags: flags,
+
+		Before: func(clictx *cli.Context) error {
+			if cfg.File() == "" {
+				return errValidateNoConfig
+			}
+
+			// same settings as serve would see, but w/o the checks that need
+			// the github app credentials (or webhook secrets) and w/o creating
+			// the directories, so that it could run e.g. in ci of the config
+			// repository
+			if err := altsrc.InitInputSourceWithContext(flags, configInputSource(cfg))(clictx); err != nil {
+				return err
+			}
+			cfg.Github.WebhookSecrets = opts.githubWebhookSecrets.Value()
+
+			return cfg.ValidateSettings()
+		},
+
+		Action: func(_ *cli.Context) error {
+			fmt.Printf("Configuration is valid (%d repositories)\n", len(cfg.Repositories))
+			return nil
+		},
+	}
+}
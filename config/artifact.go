@@ -14,6 +14,7 @@ type Artifact struct {
 	regexp *regexp.Regexp `yaml:"-" json:"-"`
 
 	Destinations []*Destination `yaml:"destinations" json:"destinations"`
+	Version      string         `yaml:"version"      json:"version"`
 }
 
 var (
@@ -23,9 +24,7 @@ var (
 func (cfg *Artifact) Validate() error {
 	errs := make([]error, 0)
 
-	supportedDestinationTypes := []string{
-		DestinationGcpArtifactRegistryGeneric,
-	}
+	supportedDestinationTypes := cfg.SupportedDestinationTypes()
 
 	{ // destinations
 		for _, d := range cfg.Destinations {
@@ -43,3 +42,17 @@ func (cfg *Artifact) Validate() error {
 func (cfg *Artifact) Regexp() *regexp.Regexp {
 	return cfg.regexp
 }
+
+// ExtractVersion returns the version of the artifact given the matches of
+// its regexp (falls back to the head sha of the workflow run).
+func (cfg *Artifact) ExtractVersion(matches []string, data *TemplateData) (string, error) {
+	return extractVersion(cfg.Version, cfg.regexp, matches, data, data.HeadSHA)
+}
+
+// SupportedDestinationTypes returns the destination types that workflow artifacts
+// can be synchronised to.
+func (cfg *Artifact) SupportedDestinationTypes() []string {
+	return []string{
+		DestinationGcpArtifactRegistryGeneric,
+	}
+}
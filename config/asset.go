@@ -14,6 +14,7 @@ type Asset struct {
 	regexp *regexp.Regexp `yaml:"-" json:"-"`
 
 	Destinations []*Destination `yaml:"destinations" json:"destinations"`
+	Version      string         `yaml:"version"      json:"version"`
 }
 
 var (
@@ -23,9 +24,7 @@ var (
 func (cfg *Asset) Validate() error {
 	errs := make([]error, 0)
 
-	supportedDestinationTypes := []string{
-		DestinationGcpArtifactRegistryGeneric,
-	}
+	supportedDestinationTypes := cfg.SupportedDestinationTypes()
 
 	{ // destinations
 		for _, d := range cfg.Destinations {
@@ -43,3 +42,17 @@ func (cfg *Asset) Validate() error {
 func (cfg *Asset) Regexp() *regexp.Regexp {
 	return cfg.regexp
 }
+
+// ExtractVersion returns the version of the asset given the matches of its
+// regexp (falls back to the version of the release in data.Version).
+func (cfg *Asset) ExtractVersion(matches []string, data *TemplateData) (string, error) {
+	return extractVersion(cfg.Version, cfg.regexp, matches, data, data.Version)
+}
+
+// SupportedDestinationTypes returns the destination types that release assets
+// can be synchronised to.
+func (cfg *Asset) SupportedDestinationTypes() []string {
+	return []string{
+		DestinationGcpArtifactRegistryGeneric,
+	}
+}
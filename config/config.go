@@ -3,8 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
-	"os"
+	"maps"
 	"reflect"
+	"slices"
 
 	"github.com/flashbots/gh-artifacts-sync/utils"
 	"gopkg.in/yaml.v2"
@@ -17,10 +18,16 @@ type Config struct {
 	Repositories map[string]*Repository `yaml:"repositories" json:"repositories"`
 	Server       *Server                `yaml:"server"       json:"server"`
 	SoftDelete   *Dir                   `yaml:"soft_delete"  json:"soft_delete"`
+
+	file    string                      `yaml:"-" json:"-"`
+	origins map[string]string           `yaml:"-" json:"-"`
+	secrets map[string]struct{}         `yaml:"-" json:"-"`
+	source  map[interface{}]interface{} `yaml:"-" json:"-"`
 }
 
 var (
-	errConfigFailedToLoad = errors.New("failed to load config file")
+	errConfigFailedToLoad      = errors.New("failed to load config file")
+	errConfigInvalidRepository = errors.New("invalid repository configuration")
 )
 
 func New() *Config {
@@ -33,19 +40,57 @@ func New() *Config {
 	}
 }
 
-func Load(file string) (*Config, error) {
-	bytes, err := os.ReadFile(file)
+// Load loads the configuration from the path, that can be a file, a directory,
+// or a glob pattern (see configFiles).  Multiple files are merged together.
+func Load(path string) (*Config, error) {
+	files, err := configFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w",
+			errConfigFailedToLoad, path, err,
+		)
+	}
+
+	e := newExpander()
+	origins := make(map[string]string)
+	source := make(map[interface{}]interface{})
+
+	errs := make([]error, 0)
+	for _, file := range files {
+		tree, err := loadFile(file, e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w",
+				errConfigFailedToLoad, file, err,
+			))
+			continue
+		}
+		if err := mergeTree(source, tree, "", file, origins); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w",
+				errConfigFailedToLoad, file, err,
+			))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utils.FlattenErrors(errs)
+	}
+
+	expanded, err := yaml.Marshal(source)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s: %w",
-			errConfigFailedToLoad, file, err,
+			errConfigFailedToLoad, path, err,
 		)
 	}
+
 	cfg := &Config{}
-	if err := yaml.UnmarshalStrict(bytes, cfg); err != nil {
+	if err := yaml.UnmarshalStrict(expanded, cfg); err != nil {
 		return nil, fmt.Errorf("%w: %s: %w",
-			errConfigFailedToLoad, file, err,
+			errConfigFailedToLoad, path, err,
 		)
 	}
+	cfg.file = path
+	cfg.origins = origins
+	cfg.secrets = e.secrets
+	cfg.source = source
+
 	return cfg, nil
 }
 
@@ -57,6 +102,34 @@ func (cfg *Config) SoftMerge(another *Config) {
 	if another.Repositories != nil {
 		cfg.Repositories = another.Repositories
 	}
+
+	if another.file != "" {
+		cfg.file = another.file
+	}
+
+	if another.origins != nil {
+		cfg.origins = another.origins
+	}
+
+	if another.secrets != nil {
+		cfg.secrets = another.secrets
+	}
+
+	if another.source != nil {
+		cfg.source = another.source
+	}
+}
+
+// File returns the path to the configuration file, directory, or glob (if
+// any) that the config was loaded from.
+func (cfg *Config) File() string {
+	return cfg.file
+}
+
+// Source returns the (expanded) tree of the loaded configuration file, to be
+// used as an input source for the cli flags.
+func (cfg *Config) Source() map[interface{}]interface{} {
+	return cfg.source
 }
 
 func (cfg *Config) Validate() error {
@@ -68,11 +141,81 @@ func (cfg *Config) Validate() error {
 	}
 
 	for idx := 0; idx < val.NumField(); idx++ {
+		if !val.Field(idx).CanInterface() {
+			continue
+		}
+		if val.Type().Field(idx).Name == "Repositories" {
+			continue // validated individually below
+		}
 		if err := validate(val.Field(idx).Interface()); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
+	if err := cfg.ValidateRepositories(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// ValidateSettings validates the configuration w/o the checks that need the
+// github app credentials (or the webhook secrets), and w/o creating the
+// directories, so that the config could be checked e.g. in ci.
+func (cfg *Config) ValidateSettings() error {
+	errs := []error{}
+
+	if cfg.Dir != nil {
+		if err := cfg.Dir.ValidatePaths(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.Github != nil {
+		if err := cfg.Github.ValidateSettings(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.Log != nil {
+		if err := cfg.Log.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.Server != nil {
+		if err := cfg.Server.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.SoftDelete != nil {
+		if err := cfg.SoftDelete.ValidatePaths(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := cfg.ValidateRepositories(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// ValidateRepositories validates the repositories section only (e.g. on
+// reload, when the rest of the settings is already validated).
+func (cfg *Config) ValidateRepositories() error {
+	errs := []error{}
+
+	names := slices.Sorted(maps.Keys(cfg.Repositories))
+	for _, name := range names {
+		if err := validate(cfg.Repositories[name]); err != nil {
+			path := "repositories." + name
+			if file, known := cfg.origins[path]; known {
+				path = file + ": " + path
+			}
+			errs = append(errs, fmt.Errorf("%w: %s: %w",
+				errConfigInvalidRepository, path, err,
+			))
+		}
+	}
+
 	return utils.FlattenErrors(errs)
 }
 
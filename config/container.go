@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -10,20 +11,25 @@ import (
 )
 
 type Container struct {
+	Deletion     *Deletion      `yaml:"deletion"     json:"deletion"`
 	Destinations []*Destination `yaml:"destinations" json:"destinations"`
+	SkipTags     string         `yaml:"skip_tags"    json:"skip_tags"`
+	Tags         string         `yaml:"tags"         json:"tags"`
+
+	skipTags *regexp.Regexp `yaml:"-" json:"-"`
+	tags     *regexp.Regexp `yaml:"-" json:"-"`
 }
 
 var (
 	errContainerInvalidDestinationType = errors.New("invalid container destination type")
+	errContainerInvalidSkipTagsRegexp  = errors.New("invalid container skip-tags regexp")
+	errContainerInvalidTagsRegexp      = errors.New("invalid container tags regexp")
 )
 
 func (cfg *Container) Validate() error {
 	errs := make([]error, 0)
 
-	supportedDestinationTypes := []string{
-		DestinationGcpArtifactRegistryDocker,
-		DestinationGcpArtifactRegistryGeneric,
-	}
+	supportedDestinationTypes := cfg.SupportedDestinationTypes()
 
 	{ // destinations
 		for _, d := range cfg.Destinations {
@@ -37,5 +43,62 @@ func (cfg *Container) Validate() error {
 		}
 	}
 
+	{ // skip_tags
+		if cfg.SkipTags != "" {
+			if re, err := regexp.Compile(cfg.SkipTags); err == nil {
+				cfg.skipTags = re
+			} else {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errContainerInvalidSkipTagsRegexp, cfg.SkipTags, err,
+				))
+			}
+		}
+	}
+
+	{ // tags
+		if cfg.Tags != "" {
+			if re, err := regexp.Compile(cfg.Tags); err == nil {
+				cfg.tags = re
+			} else {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errContainerInvalidTagsRegexp, cfg.Tags, err,
+				))
+			}
+		}
+	}
+
 	return utils.FlattenErrors(errs)
 }
+
+func (cfg *Container) HasTag(tag string) bool {
+	tags, skipTags := cfg.tags, cfg.skipTags
+	if tags == nil && cfg.Tags != "" {
+		var err error
+		if tags, err = regexp.Compile(cfg.Tags); err != nil {
+			return false
+		}
+	}
+	if skipTags == nil && cfg.SkipTags != "" {
+		var err error
+		if skipTags, err = regexp.Compile(cfg.SkipTags); err != nil {
+			return false
+		}
+	}
+
+	if tags != nil && !tags.MatchString(tag) {
+		return false
+	}
+	if skipTags != nil && skipTags.MatchString(tag) {
+		return false
+	}
+	return true
+}
+
+// SupportedDestinationTypes returns the destination types that containers
+// can be synchronised to.
+func (cfg *Container) SupportedDestinationTypes() []string {
+	return []string{
+		DestinationGcpArtifactRegistryDocker,
+		DestinationGcpArtifactRegistryGeneric,
+	}
+}
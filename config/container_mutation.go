@@ -0,0 +1,31 @@
+package config
+
+// ContainerMutation configures the changes applied to the container images
+// before they are pushed to the destination.
+//
+// Note: any mutation changes the digests of the images (and of the index)
+// at the destination.
+type ContainerMutation struct {
+	Annotations map[string]string `yaml:"annotations"  json:"annotations"`
+	Labels      map[string]string `yaml:"labels"       json:"labels"`
+	Provenance  bool              `yaml:"provenance"   json:"provenance"`
+	StripLabels []string          `yaml:"strip_labels" json:"strip_labels"`
+}
+
+const (
+	AnnotationSource          = "org.opencontainers.image.source"
+	AnnotationSourceDigest    = "net.flashbots.gh-artifacts-sync.source.digest"
+	AnnotationSourceReference = "net.flashbots.gh-artifacts-sync.source.reference"
+	AnnotationSyncedAt        = "net.flashbots.gh-artifacts-sync.synced-at"
+)
+
+func (cfg *ContainerMutation) IsEmpty() bool {
+	return cfg == nil || (len(cfg.Annotations) == 0 &&
+		len(cfg.Labels) == 0 &&
+		!cfg.Provenance &&
+		len(cfg.StripLabels) == 0)
+}
+
+func (cfg *ContainerMutation) MutatesConfig() bool {
+	return cfg != nil && (len(cfg.Labels) > 0 || len(cfg.StripLabels) > 0)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+// Deletion enables propagation of the deletions of the sources (releases,
+// container versions) to the destinations.
+type Deletion struct {
+	GracePeriod time.Duration `yaml:"grace_period" json:"grace_period"`
+	Protected   []string      `yaml:"protected"    json:"protected"`
+
+	protected []*regexp.Regexp `yaml:"-" json:"-"`
+}
+
+var (
+	errDeletionInvalidGracePeriod     = errors.New("invalid deletion grace period")
+	errDeletionInvalidProtectedRegexp = errors.New("invalid deletion protected regexp")
+)
+
+func (cfg *Deletion) Validate() error {
+	errs := make([]error, 0)
+
+	{ // grace_period
+		if cfg.GracePeriod < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDeletionInvalidGracePeriod, cfg.GracePeriod,
+			))
+		}
+	}
+
+	{ // protected
+		cfg.protected = make([]*regexp.Regexp, 0, len(cfg.Protected))
+		for _, regex := range cfg.Protected {
+			if re, err := regexp.Compile(regex); err == nil {
+				cfg.protected = append(cfg.protected, re)
+			} else {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errDeletionInvalidProtectedRegexp, regex, err,
+				))
+			}
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// IsProtected returns true if the version (or tag) must never be deleted from
+// the destinations.
+func (cfg *Deletion) IsProtected(version string) bool {
+	protected := cfg.protected
+	if protected == nil && len(cfg.Protected) > 0 {
+		var err error
+		if protected, err = compileRegexps(cfg.Protected); err != nil {
+			return true // better safe than sorry
+		}
+	}
+
+	for _, re := range protected {
+		if re.MatchString(version) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -12,30 +13,72 @@ import (
 )
 
 type Destination struct {
-	Type      string   `yaml:"type"      json:"type"`
-	Path      string   `yaml:"path"      json:"path"`
-	Package   string   `yaml:"package"   json:"package"`
-	Platforms []string `yaml:"platforms" json:"platforms"`
+	Type      string             `yaml:"type"      json:"type"`
+	Path      string             `yaml:"path"      json:"path"`
+	Package   string             `yaml:"package"   json:"package"`
+	Version   string             `yaml:"version"   json:"version"`
+	Filename  string             `yaml:"filename"  json:"filename"`
+	Include   []string           `yaml:"include"   json:"include"`
+	Exclude   []string           `yaml:"exclude"   json:"exclude"`
+	Platforms []string           `yaml:"platforms" json:"platforms"`
+	Tagless   string             `yaml:"tagless"   json:"tagless"`
+	Mutate    *ContainerMutation `yaml:"mutate"    json:"mutate"`
+	Retention *Retention         `yaml:"retention" json:"retention"`
+
+	exclude []*regexp.Regexp `yaml:"-" json:"-"`
+	include []*regexp.Regexp `yaml:"-" json:"-"`
 }
 
 var (
 	errDestinationInvalidType             = errors.New("invalid destination type")
 	errDestinationDoesNotSupportPlatforms = errors.New("destination type does not support platforms option")
 	errDestinationInvalidPlatform         = errors.New("invalid platform")
+	errDestinationInvalidTagless          = errors.New("invalid tag-less mode")
+	errDestinationDoesNotSupportTagless   = errors.New("destination type does not support tagless option")
+	errDestinationDoesNotSupportMutate    = errors.New("destination type does not support mutate option")
+	errDestinationDoesNotSupportVersion   = errors.New("destination type does not support version option")
+	errDestinationDoesNotSupportFilename  = errors.New("destination type does not support filename option")
+	errDestinationInvalidTemplate         = errors.New("invalid destination template")
+	errDestinationDoesNotSupportInclude   = errors.New("destination type does not support include option")
+	errDestinationDoesNotSupportExclude   = errors.New("destination type does not support exclude option")
+	errDestinationInvalidIncludeRegexp    = errors.New("invalid destination include regexp")
+	errDestinationInvalidExcludeRegexp    = errors.New("invalid destination exclude regexp")
+	errDestinationDoesNotSupportRetention = errors.New("destination type does not support retention option")
+	errDestinationRetentionIsTemplated    = errors.New("destination with retention can not use templates in path or package")
 )
 
 const (
 	DestinationGcpArtifactRegistryDocker  = "gcp.artifactregistry.docker"
 	DestinationGcpArtifactRegistryGeneric = "gcp.artifactregistry.generic"
+	DestinationGcpArtifactRegistryMaven   = "gcp.artifactregistry.maven"
+	DestinationGcpArtifactRegistryNpm     = "gcp.artifactregistry.npm"
 )
 
-func (cfg *Destination) Validate() error {
-	errs := make([]error, 0)
+const (
+	DestinationTaglessSkip   = "skip"   // do not sync tag-less versions (default)
+	DestinationTaglessDigest = "digest" // push tag-less versions by digest only
+	DestinationTaglessTag    = "tag"    // push tag-less versions with `sha256-...` pseudo-tag
+)
 
-	allDestinations := []string{
+var (
+	destinationTypes = []string{
 		DestinationGcpArtifactRegistryDocker,
 		DestinationGcpArtifactRegistryGeneric,
+		DestinationGcpArtifactRegistryMaven,
+		DestinationGcpArtifactRegistryNpm,
+	}
+
+	destinationTaglessModes = []string{
+		DestinationTaglessSkip,
+		DestinationTaglessDigest,
+		DestinationTaglessTag,
 	}
+)
+
+func (cfg *Destination) Validate() error {
+	errs := make([]error, 0)
+
+	allDestinations := destinationTypes
 
 	{ // type
 		if !slices.Contains(allDestinations, cfg.Type) {
@@ -45,12 +88,211 @@ func (cfg *Destination) Validate() error {
 		}
 	}
 
+	{ // tagless
+		allTaglessModes := destinationTaglessModes
+
+		if cfg.Tagless != "" {
+			if cfg.Type != DestinationGcpArtifactRegistryDocker {
+				errs = append(errs, fmt.Errorf("%w: %s",
+					errDestinationDoesNotSupportTagless, cfg.Type,
+				))
+			} else if !slices.Contains(allTaglessModes, cfg.Tagless) {
+				errs = append(errs, fmt.Errorf("%w: %s (must be one of: %s)",
+					errDestinationInvalidTagless, cfg.Tagless, strings.Join(allTaglessModes, ","),
+				))
+			}
+		}
+	}
+
+	{ // mutate
+		if cfg.Mutate != nil && cfg.Type != DestinationGcpArtifactRegistryDocker {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDestinationDoesNotSupportMutate, cfg.Type,
+			))
+		}
+	}
+
+	{ // version, filename
+		if cfg.Version != "" && cfg.Type != DestinationGcpArtifactRegistryGeneric {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDestinationDoesNotSupportVersion, cfg.Type,
+			))
+		}
+		if cfg.Filename != "" && cfg.Type != DestinationGcpArtifactRegistryGeneric {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDestinationDoesNotSupportFilename, cfg.Type,
+			))
+		}
+	}
+
+	{ // include, exclude
+		if len(cfg.Include) > 0 && cfg.Type != DestinationGcpArtifactRegistryGeneric {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDestinationDoesNotSupportInclude, cfg.Type,
+			))
+		}
+		if len(cfg.Exclude) > 0 && cfg.Type != DestinationGcpArtifactRegistryGeneric {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDestinationDoesNotSupportExclude, cfg.Type,
+			))
+		}
+
+		if include, err := compileRegexps(cfg.Include); err == nil {
+			cfg.include = include
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %w",
+				errDestinationInvalidIncludeRegexp, err,
+			))
+		}
+
+		if exclude, err := compileRegexps(cfg.Exclude); err == nil {
+			cfg.exclude = exclude
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %w",
+				errDestinationInvalidExcludeRegexp, err,
+			))
+		}
+	}
+
+	{ // retention
+		if cfg.Retention != nil {
+			if cfg.Type != DestinationGcpArtifactRegistryGeneric {
+				errs = append(errs, fmt.Errorf("%w: %s",
+					errDestinationDoesNotSupportRetention, cfg.Type,
+				))
+			}
+			if strings.Contains(cfg.Path, "{{") || strings.Contains(cfg.Package, "{{") {
+				errs = append(errs, fmt.Errorf("%w: %s: %s",
+					errDestinationRetentionIsTemplated, cfg.Path, cfg.Package,
+				))
+			}
+		}
+	}
+
+	{ // templates
+		for name, text := range map[string]string{
+			"path":     cfg.Path,
+			"package":  cfg.Package,
+			"version":  cfg.Version,
+			"filename": cfg.Filename,
+		} {
+			if _, err := parseTemplate(name, text); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errDestinationInvalidTemplate, name, err,
+				))
+			}
+		}
+	}
+
 	return utils.FlattenErrors(errs)
 }
 
+// Render returns a copy of the destination with templated fields (`path`,
+// `package`, and `version`) rendered against the data.
+func (cfg *Destination) Render(data *TemplateData) (*Destination, error) {
+	_data := TemplateData{}
+	if data != nil {
+		_data = *data
+	}
+	if _data.Platform == "" && len(cfg.Platforms) == 1 {
+		_data.Platform = cfg.Platforms[0]
+	}
+
+	dst := *cfg
+
+	for name, field := range map[string]*string{
+		"path":    &dst.Path,
+		"package": &dst.Package,
+		"version": &dst.Version,
+	} {
+		res, err := renderTemplate(name, *field, &_data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w",
+				errDestinationInvalidTemplate, name, err,
+			)
+		}
+		*field = res
+	}
+
+	return &dst, nil
+}
+
+// RenderFilename renders the name of the file to upload (data.Filename is
+// the name of the file in the downloaded archive, and is used as-is when
+// the destination does not override it).
+func (cfg *Destination) RenderFilename(data *TemplateData) (string, error) {
+	if cfg.Filename == "" {
+		return data.Filename, nil
+	}
+
+	res, err := renderTemplate("filename", cfg.Filename, data)
+	if err != nil {
+		return "", fmt.Errorf("%w: filename: %w",
+			errDestinationInvalidTemplate, err,
+		)
+	}
+
+	return res, nil
+}
+
+// IncludesFile returns true if the file (the path of the member of the
+// downloaded archive) should be uploaded to the destination: it must match
+// any of `include` regexps (if there are some), and none of `exclude` ones.
+//
+// the regexps are compiled on demand when the destination did not go through
+// Validate (e.g. it was restored from the persisted job).  if they can not be
+// compiled, nothing is included.
+func (cfg *Destination) IncludesFile(name string) bool {
+	include, exclude := cfg.include, cfg.exclude
+	if include == nil && len(cfg.Include) > 0 {
+		var err error
+		if include, err = compileRegexps(cfg.Include); err != nil {
+			return false
+		}
+	}
+	if exclude == nil && len(cfg.Exclude) > 0 {
+		var err error
+		if exclude, err = compileRegexps(cfg.Exclude); err != nil {
+			return false
+		}
+	}
+
+	for _, re := range exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+	for _, re := range include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (cfg *Destination) HasPlatform(p *cr.Platform) bool {
 	if len(cfg.Platforms) == 0 {
 		return true
 	}
 	return slices.Contains(cfg.Platforms, p.String())
 }
+
+func (cfg *Destination) SyncsTagless() bool {
+	return cfg.Tagless == DestinationTaglessDigest || cfg.Tagless == DestinationTaglessTag
+}
+
+func compileRegexps(regexps []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(regexps))
+	for _, regex := range regexps {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", regex, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDestinationIncludesFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		file    string
+		want    bool
+	}{
+		{name: "no filters", file: "foo.tar.gz", want: true},
+		{name: "included", include: []string{`\.tar\.gz$`}, file: "foo.tar.gz", want: true},
+		{name: "not included", include: []string{`\.tar\.gz$`}, file: "foo.zip", want: false},
+		{name: "excluded", exclude: []string{`\.sha256$`}, file: "foo.sha256", want: false},
+		{name: "not excluded", exclude: []string{`\.sha256$`}, file: "foo.tar.gz", want: true},
+		{name: "exclude wins", include: []string{`^foo`}, exclude: []string{`\.sha256$`}, file: "foo.sha256", want: false},
+		{name: "any include", include: []string{`\.zip$`, `\.tar\.gz$`}, file: "foo.tar.gz", want: true},
+		{name: "invalid regexp", include: []string{`(`}, file: "foo", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := &Destination{
+				Type:    DestinationGcpArtifactRegistryGeneric,
+				Include: tt.include,
+				Exclude: tt.exclude,
+			}
+			_ = dst.Validate()
+			if got := dst.IncludesFile(tt.file); got != tt.want {
+				t.Errorf("IncludesFile(%q) after validate = %v, want %v", tt.file, got, tt.want)
+			}
+
+			// the persisted jobs carry the destinations as json
+			bytes, err := json.Marshal(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored := &Destination{}
+			if err := json.Unmarshal(bytes, restored); err != nil {
+				t.Fatal(err)
+			}
+			if got := restored.IncludesFile(tt.file); got != tt.want {
+				t.Errorf("IncludesFile(%q) after json round-trip = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -9,8 +9,10 @@ import (
 )
 
 type Dir struct {
-	Downloads string `yaml:"downloads"   json:"downloads"`
-	Jobs      string `yaml:"jobs"        json:"jobs"`
+	Deliveries string `yaml:"deliveries"  json:"deliveries"`
+	Downloads  string `yaml:"downloads"   json:"downloads"`
+	Jobs       string `yaml:"jobs"        json:"jobs"`
+	State      string `yaml:"state"       json:"state"`
 }
 
 var (
@@ -22,15 +24,15 @@ var (
 func (cfg *Dir) Validate() error {
 	errs := make([]error, 0)
 
-	for _, dir := range []string{cfg.Downloads, cfg.Jobs} {
+	for _, dir := range []string{cfg.Deliveries, cfg.Downloads, cfg.Jobs, cfg.State} {
 		if dir == "" {
 			continue
 		}
 		if info, err := os.Stat(dir); err != nil {
-			if !os.IsNotExist(err) {
-				if errMkdir := os.Mkdir(dir, 0640); errMkdir != nil {
+			if os.IsNotExist(err) {
+				if errMkdir := os.Mkdir(dir, 0750); errMkdir != nil {
 					errs = append(errs, fmt.Errorf("%w: %s: %w",
-						errDirFailedToCreate, dir, err,
+						errDirFailedToCreate, dir, errMkdir,
 					))
 				}
 			} else {
@@ -49,3 +51,30 @@ func (cfg *Dir) Validate() error {
 
 	return utils.FlattenErrors(errs)
 }
+
+// ValidatePaths checks that the existing directories are indeed directories
+// (w/o creating the missing ones).
+func (cfg *Dir) ValidatePaths() error {
+	errs := make([]error, 0)
+
+	for _, dir := range []string{cfg.Deliveries, cfg.Downloads, cfg.Jobs, cfg.State} {
+		if dir == "" {
+			continue
+		}
+		info, err := os.Stat(dir)
+		switch {
+		case os.IsNotExist(err):
+			// will be created
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%w: %s: %w",
+				errDirFailedToAccess, dir, err,
+			))
+		case !info.IsDir():
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errDirNotDirectory, dir,
+			))
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/gcp"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	referenceFile      = "file:"
+	referenceGcpSecret = "gcp-secret:"
+)
+
+var (
+	errExpandUndefinedVariable    = errors.New("undefined environment variable")
+	errExpandUnterminatedVariable = errors.New("unterminated environment variable reference")
+	errExpandInvalidVariable      = errors.New("invalid environment variable name")
+	errExpandFailedToResolve      = errors.New("failed to resolve secret reference")
+)
+
+// expander expands environment variables (`${VAR}`, `${VAR:-default}`) and
+// references to secrets (`${file:/path}`, `${gcp-secret:projects/.../versions/N}`)
+// in the string values of the configuration tree.
+type expander struct {
+	gcp     *gcp.Client
+	secrets map[string]struct{}
+}
+
+func newExpander() *expander {
+	return &expander{
+		secrets: make(map[string]struct{}),
+	}
+}
+
+// expandTree expands the tree in place.  typ is the type of the config field
+// the node is decoded into (nil if unknown), and is used to tell which of the
+// expanded values must become numbers or booleans (all others remain strings).
+func (e *expander) expandTree(node interface{}, path string, typ reflect.Type) (interface{}, error) {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		errs := make([]error, 0)
+		for k, v := range n {
+			_v, err := e.expandTree(v, path+"."+fmt.Sprint(k), expandChildType(typ, k))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			n[k] = _v
+		}
+		return n, errors.Join(errs...)
+
+	case []interface{}:
+		errs := make([]error, 0)
+		for idx, v := range n {
+			_v, err := e.expandTree(v, fmt.Sprintf("%s[%d]", path, idx), expandChildType(typ, nil))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			n[idx] = _v
+		}
+		return n, errors.Join(errs...)
+
+	case string:
+		str, err := e.expandString(n)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.TrimPrefix(path, "."), err)
+		}
+		if str != n && expandIsScalarType(typ) {
+			// expanded values of numeric or boolean fields are scalars as if
+			// they were written in place (so that `id: ${GITHUB_APP_ID}`
+			// yields a number)
+			var scalar interface{}
+			if err := yaml.Unmarshal([]byte(str), &scalar); err == nil {
+				switch scalar.(type) {
+				case bool, int, int64, uint64, float64:
+					return scalar, nil
+				}
+			}
+		}
+		return str, nil
+	}
+
+	return node, nil
+}
+
+func (e *expander) expandString(str string) (string, error) {
+	if !strings.Contains(str, "$") {
+		return str, nil
+	}
+
+	var res strings.Builder
+	for len(str) > 0 {
+		idx := strings.IndexByte(str, '$')
+		if idx == -1 || idx == len(str)-1 {
+			res.WriteString(str)
+			break
+		}
+
+		res.WriteString(str[:idx])
+		str = str[idx:]
+
+		switch str[1] {
+		case '$': // escaped
+			res.WriteByte('$')
+			str = str[2:]
+
+		case '{':
+			if ref := str[2:]; strings.HasPrefix(ref, referenceFile) || strings.HasPrefix(ref, referenceGcpSecret) {
+				end := matchingBrace(str)
+				if end == -1 {
+					return "", fmt.Errorf("%w: %s",
+						errExpandUnterminatedVariable, str,
+					)
+				}
+				// the reference itself can use environment variables
+				ref, err := e.expandString(str[2:end])
+				if err != nil {
+					return "", err
+				}
+				secret, err := e.resolveReference(ref)
+				if err != nil {
+					return "", err
+				}
+				res.WriteString(secret)
+				str = str[end+1:]
+				continue
+			}
+
+			end := strings.IndexByte(str, '}')
+			if end == -1 {
+				return "", fmt.Errorf("%w: %s",
+					errExpandUnterminatedVariable, str,
+				)
+			}
+
+			name, fallback, hasFallback := strings.Cut(str[2:end], ":-")
+			if !isValidVariableName(name) {
+				return "", fmt.Errorf("%w: %s",
+					errExpandInvalidVariable, name,
+				)
+			}
+
+			value, isDefined := os.LookupEnv(name)
+			switch {
+			case isDefined && (value != "" || !hasFallback):
+				res.WriteString(value)
+			case hasFallback:
+				res.WriteString(fallback)
+			default:
+				return "", fmt.Errorf("%w: %s",
+					errExpandUndefinedVariable, name,
+				)
+			}
+			str = str[end+1:]
+
+		default:
+			res.WriteByte('$')
+			str = str[1:]
+		}
+	}
+
+	return res.String(), nil
+}
+
+func (e *expander) resolveReference(str string) (string, error) {
+	var (
+		secret []byte
+		err    error
+	)
+
+	switch {
+	default:
+		return "", fmt.Errorf("%w: %s",
+			errExpandFailedToResolve, str,
+		)
+
+	case strings.HasPrefix(str, referenceFile):
+		path := strings.TrimPrefix(str, referenceFile)
+		secret, err = os.ReadFile(path)
+		if err == nil {
+			secret = []byte(strings.TrimRight(string(secret), "\r\n"))
+		}
+
+	case strings.HasPrefix(str, referenceGcpSecret):
+		if e.gcp == nil {
+			e.gcp = gcp.New()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		secret, err = e.gcp.SecretManagerAccess(ctx, strings.TrimPrefix(str, referenceGcpSecret))
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w",
+			errExpandFailedToResolve, str, err,
+		)
+	}
+
+	e.secrets[string(secret)] = struct{}{}
+
+	return string(secret), nil
+}
+
+// matchingBrace returns the index of the brace that closes the one at the
+// index 1 of the string (i.e. the end of `${...}` with nested references).
+func matchingBrace(str string) int {
+	depth := 0
+	for idx := 1; idx < len(str); idx++ {
+		switch str[idx] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return idx
+			}
+		}
+	}
+	return -1
+}
+
+// expandChildType returns the type of the child node (by its key, or nil for
+// the elements of the list) of the node of type typ.
+func expandChildType(typ reflect.Type, key interface{}) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Map, reflect.Slice:
+		return typ.Elem()
+
+	case reflect.Struct:
+		name := fmt.Sprint(key)
+		for idx := 0; idx < typ.NumField(); idx++ {
+			field := typ.Field(idx)
+			if tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); tag == name {
+				return field.Type
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandIsScalarType returns true if the field of the type is numeric or
+// boolean (durations are strings in the config).
+func expandIsScalarType(typ reflect.Type) bool {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ == reflect.TypeOf(time.Duration(0)) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func isValidVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for idx, c := range name {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+			// ok
+		case c >= '0' && c <= '9' && idx > 0:
+			// ok
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandString(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("EXPAND_TEST_SET", "value")
+	t.Setenv("EXPAND_TEST_EMPTY", "")
+	t.Setenv("EXPAND_TEST_SECRET_PATH", secret)
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr error
+	}{
+		{name: "no variables", in: "plain", want: "plain"},
+		{name: "variable", in: "${EXPAND_TEST_SET}", want: "value"},
+		{name: "embedded", in: "a-${EXPAND_TEST_SET}-b", want: "a-value-b"},
+		{name: "escaped", in: "$${EXPAND_TEST_SET}", want: "${EXPAND_TEST_SET}"},
+		{name: "lone dollar", in: "cost $5 $", want: "cost $5 $"},
+		{name: "fallback on unset", in: "${EXPAND_TEST_UNSET:-def}", want: "def"},
+		{name: "fallback on empty", in: "${EXPAND_TEST_EMPTY:-def}", want: "def"},
+		{name: "empty without fallback", in: "x${EXPAND_TEST_EMPTY}x", want: "xx"},
+		{name: "undefined", in: "${EXPAND_TEST_UNSET}", wantErr: errExpandUndefinedVariable},
+		{name: "unterminated", in: "${EXPAND_TEST_SET", wantErr: errExpandUnterminatedVariable},
+		{name: "invalid name", in: "${1ABC}", wantErr: errExpandInvalidVariable},
+		{name: "file reference", in: "${file:" + secret + "}", want: "s3cr3t"},
+		{name: "embedded file reference", in: "Bearer ${file:" + secret + "}!", want: "Bearer s3cr3t!"},
+		{name: "file reference w/ variable", in: "${file:${EXPAND_TEST_SECRET_PATH}}", want: "s3cr3t"},
+		{name: "escaped file reference", in: "$${file:" + secret + "}", want: "${file:" + secret + "}"},
+		{name: "bare file prefix", in: "file:" + secret, want: "file:" + secret},
+		{name: "bare gcp secret prefix", in: "gcp-secret:projects/p/secrets/s/versions/1", want: "gcp-secret:projects/p/secrets/s/versions/1"},
+		{name: "missing file", in: "${file:" + secret + ".missing}", wantErr: errExpandFailedToResolve},
+		{name: "unterminated reference", in: "${file:${EXPAND_TEST_SECRET_PATH}", wantErr: errExpandUnterminatedVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newExpander().expandString(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTree(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("EXPAND_TEST_APP_ID", "12345")
+	t.Setenv("EXPAND_TEST_OCTAL", "0755")
+	t.Setenv("EXPAND_TEST_FLOAT", "1.10")
+	t.Setenv("EXPAND_TEST_BOOL", "on")
+	t.Setenv("EXPAND_TEST_SCI", "123e4567")
+	t.Setenv("EXPAND_TEST_TRUE", "true")
+	t.Setenv("EXPAND_TEST_INTERVAL", "5m")
+	t.Setenv("EXPAND_TEST_SECRET_PATH", secret)
+
+	tree := map[interface{}]interface{}{
+		"github": map[interface{}]interface{}{
+			"app": map[interface{}]interface{}{
+				"id":          "${EXPAND_TEST_APP_ID}",
+				"private_key": "${EXPAND_TEST_SCI}",
+			},
+			"disable_webhook": "${EXPAND_TEST_TRUE}",
+			"poll_interval":   "${EXPAND_TEST_INTERVAL}",
+			"webhook_secret":  "${file:${EXPAND_TEST_SECRET_PATH}}",
+		},
+		"repositories": map[interface{}]interface{}{
+			"org/repo": map[interface{}]interface{}{
+				"npm": map[interface{}]interface{}{
+					"${EXPAND_TEST_OCTAL}": map[interface{}]interface{}{
+						"destinations": []interface{}{
+							map[interface{}]interface{}{
+								"type":     "gcp.artifactregistry.npm",
+								"path":     "${EXPAND_TEST_FLOAT}",
+								"package":  "${EXPAND_TEST_OCTAL}",
+								"version":  "${EXPAND_TEST_BOOL}",
+								"filename": "file:{{ .Version }}",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	e := newExpander()
+	if _, err := e.expandTree(tree, "", reflect.TypeOf(Config{})); err != nil {
+		t.Fatal(err)
+	}
+
+	github := tree["github"].(map[interface{}]interface{})
+	app := github["app"].(map[interface{}]interface{})
+	dst := tree["repositories"].(map[interface{}]interface{})["org/repo"].(map[interface{}]interface{})["npm"].(map[interface{}]interface{})["${EXPAND_TEST_OCTAL}"].(map[interface{}]interface{})["destinations"].([]interface{})[0].(map[interface{}]interface{})
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "numeric field", got: app["id"], want: 12345},
+		{name: "boolean field", got: github["disable_webhook"], want: true},
+		{name: "duration field", got: github["poll_interval"], want: "5m"},
+		{name: "string field with exponent", got: app["private_key"], want: "123e4567"},
+		{name: "string field with octal", got: dst["package"], want: "0755"},
+		{name: "string field with float", got: dst["path"], want: "1.10"},
+		{name: "string field with bool", got: dst["version"], want: "on"},
+		{name: "secret reference", got: github["webhook_secret"], want: "s3cr3t"},
+		{name: "not a reference", got: dst["filename"], want: "file:{{ .Version }}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %#v, want %#v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if _, isSecret := e.secrets["s3cr3t"]; !isSecret {
+		t.Error("resolved secret must be remembered for redaction")
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	errConfigNoFiles      = errors.New("no configuration files found")
+	errConfigDuplicateKey = errors.New("duplicate configuration key")
+)
+
+// configFiles resolves the path into the list of configuration files.  The
+// path can be:
+//
+//   - a file;
+//   - a directory (all `.yaml` and `.yml` files in it and in its
+//     subdirectories are loaded, except for the hidden ones, e.g. `.github`);
+//   - a glob pattern (e.g. `config/*.yaml`).
+//
+// The files are returned in lexical order.
+func configFiles(path string) ([]string, error) {
+	files := make([]string, 0)
+
+	switch {
+	case strings.ContainsAny(path, "*?["):
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && !info.IsDir() {
+				files = append(files, m)
+			}
+		}
+
+	default:
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return []string{path}, nil
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != path {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if ext := filepath.Ext(p); ext == ".yaml" || ext == ".yml" {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errConfigNoFiles
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// loadFile reads, expands, and checks the structure of a single
+// configuration file.
+func loadFile(file string, e *expander) (map[interface{}]interface{}, error) {
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(bytes, &tree); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.expandTree(tree, "", reflect.TypeOf(Config{})); err != nil {
+		return nil, err
+	}
+
+	expanded, err := yaml.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(expanded, &Config{}); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// mergeTree merges the src tree (loaded from the file) into dst.  Nested maps
+// are merged recursively, with the exception of individual repositories that
+// must be defined in one file only.  Any other key defined more than once is
+// reported as a conflict.
+//
+// The origins map is updated with the file that each key was defined in.
+func mergeTree(
+	dst, src map[interface{}]interface{},
+	path, file string,
+	origins map[string]string,
+) error {
+	errs := make([]error, 0)
+
+	keys := make([]interface{}, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b interface{}) int {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+
+	for _, k := range keys {
+		v := src[k]
+
+		_path := fmt.Sprint(k)
+		if path != "" {
+			_path = path + "." + _path
+		}
+
+		_src, srcIsMap := v.(map[interface{}]interface{})
+		existing, exists := dst[k]
+
+		if !exists {
+			origins[_path] = file
+			if !srcIsMap {
+				dst[k] = v
+				continue
+			}
+			_dst := make(map[interface{}]interface{}, len(_src))
+			dst[k] = _dst
+			if err := mergeTree(_dst, _src, _path, file, origins); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		_dst, dstIsMap := existing.(map[interface{}]interface{})
+		if srcIsMap && dstIsMap && path != "repositories" {
+			if err := mergeTree(_dst, _src, _path, file, origins); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%w: %s (already defined in %s)",
+			errConfigDuplicateKey, _path, origins[_path],
+		))
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// Checksum returns the checksum of the configuration file(s) the config was
+// loaded from, to detect the changes.
+func (cfg *Config) Checksum() ([32]byte, error) {
+	files, err := configFiles(cfg.file)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	hash := sha256.New()
+	for _, file := range files {
+		bytes, err := os.ReadFile(file)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", file, len(bytes))
+		hash.Write(bytes)
+	}
+
+	var checksum [32]byte
+	copy(checksum[:], hash.Sum(nil))
+
+	return checksum, nil
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    string
+		origins map[string]string
+		wantErr string
+	}{
+		{
+			name: "disjoint sections",
+			files: []string{
+				"server: {listen_address: 0.0.0.0:8080}",
+				"log: {level: debug}",
+			},
+			want: "{log: {level: debug}, server: {listen_address: 0.0.0.0:8080}}",
+			origins: map[string]string{
+				"log":                   "1.yaml",
+				"log.level":             "1.yaml",
+				"server":                "0.yaml",
+				"server.listen_address": "0.yaml",
+			},
+		},
+		{
+			name: "nested sections are merged",
+			files: []string{
+				"github: {app: {id: 1}}",
+				"github: {app: {installation_id: 2}, webhook_secret: s}",
+			},
+			want: "{github: {app: {id: 1, installation_id: 2}, webhook_secret: s}}",
+			origins: map[string]string{
+				"github":                     "0.yaml",
+				"github.app":                 "0.yaml",
+				"github.app.id":              "0.yaml",
+				"github.app.installation_id": "1.yaml",
+				"github.webhook_secret":      "1.yaml",
+			},
+		},
+		{
+			name: "different repositories",
+			files: []string{
+				"repositories: {org/a: {releases: {}}}",
+				"repositories: {org/b: {releases: {}}}",
+			},
+			want: "{repositories: {org/a: {releases: {}}, org/b: {releases: {}}}}",
+		},
+		{
+			name: "duplicate scalar",
+			files: []string{
+				"log: {level: debug}",
+				"log: {level: info}",
+			},
+			wantErr: "log.level (already defined in 0.yaml)",
+		},
+		{
+			name: "repositories are not merged",
+			files: []string{
+				"repositories: {org/a: {releases: {}}}",
+				"repositories: {org/a: {containers: {}}}",
+			},
+			wantErr: "repositories.org/a (already defined in 0.yaml)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := make(map[interface{}]interface{})
+			origins := make(map[string]string)
+
+			var err error
+			for idx, file := range tt.files {
+				src := make(map[interface{}]interface{})
+				if err := yaml.Unmarshal([]byte(file), &src); err != nil {
+					t.Fatal(err)
+				}
+				if err = mergeTree(dst, src, "", fmt.Sprintf("%d.yaml", idx), origins); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr != "" {
+				if !errors.Is(err, errConfigDuplicateKey) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("mergeTree() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeTree() error = %v", err)
+			}
+
+			want := make(map[interface{}]interface{})
+			if err := yaml.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(dst, want) {
+				t.Errorf("mergeTree() = %v, want %v", dst, want)
+			}
+			for path, file := range tt.origins {
+				if origins[path] != file {
+					t.Errorf("origin of %s = %q, want %q", path, origins[path], file)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, file := range []string{
+		"base.yaml",
+		"teams/infra.yml",
+		"teams/rust.yaml",
+		"teams/readme.md",
+		".hidden.yaml",
+		".github/workflows/ci.yml",
+		"teams/.git/config.yaml",
+	} {
+		file = filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		files []string
+	}{
+		{"directory", dir, []string{"base.yaml", "teams/infra.yml", "teams/rust.yaml"}},
+		{"subdirectory", filepath.Join(dir, "teams"), []string{"teams/infra.yml", "teams/rust.yaml"}},
+		{"hidden directory given explicitly", filepath.Join(dir, ".github"), []string{".github/workflows/ci.yml"}},
+		{"glob", filepath.Join(dir, "teams", "*.yaml"), []string{"teams/rust.yaml"}},
+		{"file", filepath.Join(dir, "base.yaml"), []string{"base.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := configFiles(tt.path)
+			if err != nil {
+				t.Fatalf("configFiles() error = %v", err)
+			}
+			want := make([]string, 0, len(tt.files))
+			for _, file := range tt.files {
+				want = append(want, filepath.Join(dir, file))
+			}
+			if !slices.Equal(files, want) {
+				t.Errorf("configFiles() = %v, want %v", files, want)
+			}
+		})
+	}
+}
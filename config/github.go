@@ -1,20 +1,158 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
 
 type Github struct {
-	App           *GithubApp `yaml:"app"            json:"app"`
-	WebhookSecret string     `yaml:"webhook_secret" json:"webhook_secret"`
+	App             *GithubApp    `yaml:"app"               json:"app"`
+	BaseURL         string        `yaml:"base_url"          json:"base_url"`
+	CatchUpInterval time.Duration `yaml:"catch_up_interval" json:"catch_up_interval"`
+	DisableWebhook  bool          `yaml:"disable_webhook"   json:"disable_webhook"`
+	PollInterval    time.Duration `yaml:"poll_interval"     json:"poll_interval"`
+	RegistryHost    string        `yaml:"registry_host"     json:"registry_host"`
+	UploadURL       string        `yaml:"upload_url"        json:"upload_url"`
+	WebhookSecret   string        `yaml:"webhook_secret"    json:"webhook_secret"`
+	WebhookSecrets  []string      `yaml:"webhook_secrets"   json:"webhook_secrets"`
 }
 
+const (
+	githubServerURL = "https://github.com"
+)
+
 var (
+	errGithubInvalidBaseURL           = errors.New("invalid github base url")
+	errGithubInvalidCatchUpInterval   = errors.New("invalid github catch-up interval")
+	errGithubInvalidPollInterval      = errors.New("invalid github poll interval")
+	errGithubInvalidRegistryHost      = errors.New("invalid github registry host")
+	errGithubInvalidUploadURL         = errors.New("invalid github upload url")
+	errGithubInvalidWebhookSecret     = errors.New("invalid github webhook secret")
+	errGithubUploadURLWithoutBaseURL  = errors.New("must provide github base url along with upload url")
 	errGithubMustProvideWebhookSecret = errors.New("must provide github webhook secret")
+	errGithubNoEventSources           = errors.New("must enable github webhook and/or polling")
 )
 
 func (cfg *Github) Validate() error {
-	if cfg.WebhookSecret == "" {
+	if err := cfg.ValidateSettings(); err != nil {
+		return err
+	}
+
+	if !cfg.DisableWebhook && len(cfg.AcceptedWebhookSecrets()) == 0 {
 		return errGithubMustProvideWebhookSecret
 	}
 
 	return nil
 }
+
+// ValidateSettings validates the github section w/o the app credentials and
+// w/o requiring the webhook secret.
+func (cfg *Github) ValidateSettings() error {
+	if cfg.BaseURL != "" {
+		if err := validateGithubURL(cfg.BaseURL); err != nil {
+			return fmt.Errorf("%w: %s: %w",
+				errGithubInvalidBaseURL, cfg.BaseURL, err,
+			)
+		}
+	}
+
+	if cfg.UploadURL != "" {
+		if cfg.BaseURL == "" {
+			return errGithubUploadURLWithoutBaseURL
+		}
+		if err := validateGithubURL(cfg.UploadURL); err != nil {
+			return fmt.Errorf("%w: %s: %w",
+				errGithubInvalidUploadURL, cfg.UploadURL, err,
+			)
+		}
+	}
+
+	if cfg.CatchUpInterval < 0 {
+		return fmt.Errorf("%w: %s",
+			errGithubInvalidCatchUpInterval, cfg.CatchUpInterval,
+		)
+	}
+
+	if cfg.PollInterval < 0 {
+		return fmt.Errorf("%w: %s",
+			errGithubInvalidPollInterval, cfg.PollInterval,
+		)
+	}
+
+	if cfg.DisableWebhook && cfg.PollInterval == 0 {
+		return errGithubNoEventSources
+	}
+
+	if cfg.RegistryHost != "" {
+		if u, err := url.Parse("//" + cfg.RegistryHost); err != nil || u.Host != cfg.RegistryHost {
+			return fmt.Errorf("%w (must be a host w/o scheme and path): %s",
+				errGithubInvalidRegistryHost, cfg.RegistryHost,
+			)
+		}
+	}
+
+	for idx, secret := range cfg.WebhookSecrets {
+		if secret == "" {
+			return fmt.Errorf("%w: empty secret at index %d",
+				errGithubInvalidWebhookSecret, idx,
+			)
+		}
+	}
+
+	return nil
+}
+
+// AcceptedWebhookSecrets returns all secrets that the webhook deliveries are
+// validated with.  the first one is the primary (i.e. the one configured on
+// github), the rest are accepted while the secret is being rotated.
+func (cfg *Github) AcceptedWebhookSecrets() []string {
+	secrets := make([]string, 0, len(cfg.WebhookSecrets)+1)
+	if cfg.WebhookSecret != "" {
+		secrets = append(secrets, cfg.WebhookSecret)
+	}
+	return append(secrets, cfg.WebhookSecrets...)
+}
+
+// IsEnterprise returns true if github enterprise server is configured
+// (instead of github.com).
+func (cfg *Github) IsEnterprise() bool {
+	return cfg.BaseURL != ""
+}
+
+// EnterpriseUploadURL returns the upload url of github enterprise server
+// (which is the same as the base url, unless configured otherwise).
+func (cfg *Github) EnterpriseUploadURL() string {
+	if cfg.UploadURL != "" {
+		return cfg.UploadURL
+	}
+	return cfg.BaseURL
+}
+
+// ServerURL returns the url of the github web ui (e.g. to link the
+// repositories).
+func (cfg *Github) ServerURL() string {
+	if cfg.BaseURL == "" {
+		return githubServerURL
+	}
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return githubServerURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func validateGithubURL(str string) error {
+	u, err := url.Parse(str)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("missing host")
+	}
+	return nil
+}
@@ -19,10 +19,10 @@ type GithubApp struct {
 }
 
 var (
-	errGithubAppMustProvideID             = errors.New("must provide github app id")
-	errGithubAppMustProvideInstallationID = errors.New("must provide github app installation id")
-	errGithubAppMustProvidePrivateKey     = errors.New("must provide github app private key")
-	errGithubAppInvalidPrivateKey         = errors.New("invalid github app private key")
+	errGithubAppMustProvideID         = errors.New("must provide github app id")
+	errGithubAppInvalidInstallationID = errors.New("invalid github app installation id")
+	errGithubAppMustProvidePrivateKey = errors.New("must provide github app private key")
+	errGithubAppInvalidPrivateKey     = errors.New("invalid github app private key")
 )
 
 func (cfg *GithubApp) Validate() error {
@@ -35,8 +35,10 @@ func (cfg *GithubApp) Validate() error {
 	}
 
 	{ // installation_id
-		if cfg.InstallationID == 0 {
-			errs = append(errs, errGithubAppMustProvideInstallationID)
+		if cfg.InstallationID < 0 {
+			errs = append(errs, fmt.Errorf("%w: %d",
+				errGithubAppInvalidInstallationID, cfg.InstallationID,
+			))
 		}
 	}
 
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGithubAcceptedWebhookSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		github  Github
+		secrets []string
+	}{
+		{"none", Github{}, []string{}},
+		{"primary only", Github{WebhookSecret: "a"}, []string{"a"}},
+		{"rotation", Github{WebhookSecret: "a", WebhookSecrets: []string{"b", "c"}}, []string{"a", "b", "c"}},
+		{"list only", Github{WebhookSecrets: []string{"b", "c"}}, []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.github.AcceptedWebhookSecrets(); !slices.Equal(got, tt.secrets) {
+				t.Errorf("AcceptedWebhookSecrets() = %v, want %v", got, tt.secrets)
+			}
+		})
+	}
+}
+
+func TestGithubValidateSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		github Github
+		err    error
+	}{
+		{"defaults", Github{}, nil},
+		{"no credentials", Github{BaseURL: "https://ghe.example.com/api/v3/"}, nil},
+		{"invalid base url", Github{BaseURL: "://bad"}, errGithubInvalidBaseURL},
+		{"upload url w/o base url", Github{UploadURL: "https://ghe.example.com/api/uploads/"}, errGithubUploadURLWithoutBaseURL},
+		{"negative poll interval", Github{PollInterval: -time.Second}, errGithubInvalidPollInterval},
+		{"no event sources", Github{DisableWebhook: true}, errGithubNoEventSources},
+		{"registry host", Github{RegistryHost: "ghcr.example.com"}, nil},
+		{"registry host with scheme", Github{RegistryHost: "https://ghcr.example.com"}, errGithubInvalidRegistryHost},
+		{"registry host with path", Github{RegistryHost: "ghcr.example.com/foo"}, errGithubInvalidRegistryHost},
+		{"empty webhook secret", Github{WebhookSecrets: []string{"a", ""}}, errGithubInvalidWebhookSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.github.ValidateSettings(); !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Errorf("ValidateSettings() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+// AssetMatch is a release asset matched against the configuration.
+type AssetMatch struct {
+	Asset         *Asset
+	AssetRegexp   string
+	Data          *TemplateData
+	Release       *Release
+	ReleaseRegexp string
+}
+
+// ArtifactMatch is a workflow artifact matched against the configuration.
+type ArtifactMatch struct {
+	Artifact       *Artifact
+	ArtifactRegexp string
+	Data           *TemplateData
+}
+
+// MatchReleaseAsset returns the release/asset rules that match the asset of
+// the release (described by data.ReleaseName and data.ReleaseTag).  The
+// returned template data has the named groups of both regexps and the
+// extracted version.
+func (cfg *Repository) MatchReleaseAsset(data *TemplateData, asset string) ([]*AssetMatch, error) {
+	res := make([]*AssetMatch, 0)
+	errs := make([]error, 0)
+
+	for _, releaseRegexp := range slices.Sorted(maps.Keys(cfg.Releases)) {
+		release := cfg.Releases[releaseRegexp]
+
+		releaseMatches := release.Regexp().FindStringSubmatch(data.ReleaseName)
+		if len(releaseMatches) == 0 {
+			continue
+		}
+
+		releaseData := *data
+		releaseData.Groups = NamedGroups(release.Regexp().SubexpNames(), releaseMatches)
+
+		releaseVersion, err := release.ExtractVersion(releaseMatches, &releaseData)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		releaseData.Version = releaseVersion
+
+		for _, assetRegexp := range slices.Sorted(maps.Keys(release.Assets)) {
+			a := release.Assets[assetRegexp]
+
+			assetMatches := a.Regexp().FindStringSubmatch(asset)
+			if len(assetMatches) == 0 {
+				continue
+			}
+
+			assetData := releaseData
+			assetData.Groups = maps.Clone(releaseData.Groups)
+			maps.Copy(assetData.Groups, NamedGroups(a.Regexp().SubexpNames(), assetMatches))
+
+			version, err := a.ExtractVersion(assetMatches, &assetData)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			assetData.Version = version
+
+			res = append(res, &AssetMatch{
+				Asset:         a,
+				AssetRegexp:   assetRegexp,
+				Data:          &assetData,
+				Release:       release,
+				ReleaseRegexp: releaseRegexp,
+			})
+		}
+	}
+
+	return res, utils.FlattenErrors(errs)
+}
+
+// MatchArtifact returns the artifact rules of the workflow that match the
+// artifact.  The returned template data has the named groups of the regexp
+// and the extracted version.
+func (cfg *Workflow) MatchArtifact(data *TemplateData, artifact string) ([]*ArtifactMatch, error) {
+	res := make([]*ArtifactMatch, 0)
+	errs := make([]error, 0)
+
+	for _, artifactRegexp := range slices.Sorted(maps.Keys(cfg.Artifacts)) {
+		a := cfg.Artifacts[artifactRegexp]
+
+		matches := a.Regexp().FindStringSubmatch(artifact)
+		if len(matches) == 0 {
+			continue
+		}
+
+		artifactData := *data
+		artifactData.Groups = NamedGroups(a.Regexp().SubexpNames(), matches)
+
+		version, err := a.ExtractVersion(matches, &artifactData)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		artifactData.Version = version
+
+		res = append(res, &ArtifactMatch{
+			Artifact:       a,
+			ArtifactRegexp: artifactRegexp,
+			Data:           &artifactData,
+		})
+	}
+
+	return res, utils.FlattenErrors(errs)
+}
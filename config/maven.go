@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+type Maven struct {
+	Destinations []*Destination `yaml:"destinations" json:"destinations"`
+}
+
+var (
+	errMavenInvalidDestinationType = errors.New("invalid maven package destination type")
+)
+
+func (cfg *Maven) Validate() error {
+	errs := make([]error, 0)
+
+	supportedDestinationTypes := cfg.SupportedDestinationTypes()
+
+	{ // destinations
+		for _, d := range cfg.Destinations {
+			if !slices.Contains(supportedDestinationTypes, d.Type) {
+				errs = append(errs, fmt.Errorf("%w (must be one of: %s): %s",
+					errMavenInvalidDestinationType,
+					strings.Join(supportedDestinationTypes, ","),
+					d.Type,
+				))
+			}
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// SupportedDestinationTypes returns the destination types that maven packages
+// can be synchronised to.
+func (cfg *Maven) SupportedDestinationTypes() []string {
+	return []string{
+		DestinationGcpArtifactRegistryMaven,
+		DestinationGcpArtifactRegistryGeneric,
+	}
+}
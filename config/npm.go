@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+type Npm struct {
+	Destinations []*Destination `yaml:"destinations" json:"destinations"`
+}
+
+var (
+	errNpmInvalidDestinationType = errors.New("invalid npm package destination type")
+)
+
+func (cfg *Npm) Validate() error {
+	errs := make([]error, 0)
+
+	supportedDestinationTypes := cfg.SupportedDestinationTypes()
+
+	{ // destinations
+		for _, d := range cfg.Destinations {
+			if !slices.Contains(supportedDestinationTypes, d.Type) {
+				errs = append(errs, fmt.Errorf("%w (must be one of: %s): %s",
+					errNpmInvalidDestinationType,
+					strings.Join(supportedDestinationTypes, ","),
+					d.Type,
+				))
+			}
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// SupportedDestinationTypes returns the destination types that npm packages
+// can be synchronised to.
+func (cfg *Npm) SupportedDestinationTypes() []string {
+	return []string{
+		DestinationGcpArtifactRegistryNpm,
+		DestinationGcpArtifactRegistryGeneric,
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+const redacted = "[REDACTED]"
+
+var secretPaths = map[string]struct{}{
+	"github.app.private_key": {},
+	"github.webhook_secret":  {},
+	"github.webhook_secrets": {},
+}
+
+// MarshalYamlRedacted marshals the config into yaml with the secrets (known
+// secret fields, as well as the values resolved from secret references)
+// replaced by a placeholder.
+func (cfg *Config) MarshalYamlRedacted() ([]byte, error) {
+	bytes, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(bytes, &tree); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(cfg.redact(tree, ""))
+}
+
+func (cfg *Config) redact(node interface{}, path string) interface{} {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range n {
+			_path := fmt.Sprint(k)
+			if path != "" {
+				_path = path + "." + _path
+			}
+			n[k] = cfg.redact(v, _path)
+		}
+		return n
+
+	case []interface{}:
+		for idx, v := range n {
+			n[idx] = cfg.redact(v, path)
+		}
+		return n
+
+	case string:
+		if n == "" {
+			return n
+		}
+		if _, isSecret := secretPaths[path]; isSecret {
+			return redacted
+		}
+		if _, isSecret := cfg.secrets[n]; isSecret {
+			return redacted
+		}
+		return n
+	}
+
+	return node
+}
@@ -14,6 +14,8 @@ type Release struct {
 	AcceptDrafts      bool              `yaml:"accept_drafts"      json:"accept_drafts"`
 	AcceptPrereleases bool              `yaml:"accept_prereleases" json:"accept_prereleases"`
 	Assets            map[string]*Asset `yaml:"assets"             json:"assets"`
+	Deletion          *Deletion         `yaml:"deletion"           json:"deletion"`
+	Version           string            `yaml:"version"            json:"version"`
 }
 
 var (
@@ -23,19 +25,48 @@ var (
 func (cfg *Release) Validate() error {
 	errs := make([]error, 0)
 
-	for regex, a := range cfg.Assets {
-		if re, err := regexp.Compile(regex); err == nil {
-			a.regexp = re
-		} else {
-			errs = append(errs, fmt.Errorf("%w: %s: %w",
-				errReleaseInvalidAssetRegexp, regex, err,
-			))
+	{ // version
+		if err := validateVersionTemplate(cfg.Version, cfg.regexp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	{ // assets
+		for regex, a := range cfg.Assets {
+			if re, err := regexp.Compile(regex); err == nil {
+				a.regexp = re
+			} else {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errReleaseInvalidAssetRegexp, regex, err,
+				))
+				continue
+			}
+
+			if err := validateVersionTemplate(a.Version, cfg.regexp, a.regexp); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", regex, err))
+			}
 		}
 	}
 
 	return utils.FlattenErrors(errs)
 }
 
+// Accepts returns true if the release with the given draft and pre-release
+// markers is to be synchronised.
+func (cfg *Release) Accepts(draft, prerelease bool) bool {
+	return (!draft || cfg.AcceptDrafts) && (!prerelease || cfg.AcceptPrereleases)
+}
+
 func (cfg *Release) Regexp() *regexp.Regexp {
 	return cfg.regexp
 }
+
+// ExtractVersion returns the version of the release given the matches of
+// its regexp (falls back to the whole match).
+func (cfg *Release) ExtractVersion(matches []string, data *TemplateData) (string, error) {
+	fallback := ""
+	if len(matches) > 0 {
+		fallback = matches[0]
+	}
+	return extractVersion(cfg.Version, cfg.regexp, matches, data, fallback)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+var (
+	errConfigReloadNoFile = errors.New("config was not loaded from a file")
+	errConfigReloadFailed = errors.New("failed to reload config")
+)
+
+// Reload loads the configuration file again and returns a copy of the config
+// with repositories and log level replaced by the ones from the file.  The
+// rest of the settings (directories, github app, server) require a restart
+// and are kept as they are.
+//
+// Only the reloaded sections are validated (the rest of the sections are
+// shared with the original config, and re-validating them would modify the
+// live settings), and the original config is never modified.
+func (cfg *Config) Reload() (*Config, error) {
+	if cfg.file == "" {
+		return nil, errConfigReloadNoFile
+	}
+
+	_cfg, err := Load(cfg.file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w",
+			errConfigReloadFailed, err,
+		)
+	}
+
+	res := *cfg
+
+	res.Repositories = _cfg.Repositories
+	res.origins = _cfg.origins
+	res.secrets = _cfg.secrets
+	res.source = _cfg.source
+
+	if cfg.Log != nil {
+		log := *cfg.Log
+		if _cfg.Log != nil && _cfg.Log.Level != "" {
+			log.Level = _cfg.Log.Level
+		}
+		res.Log = &log
+	}
+
+	errs := make([]error, 0)
+	if res.Log != nil {
+		if err := res.Log.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := res.ValidateRepositories(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := utils.FlattenErrors(errs); err != nil {
+		return nil, fmt.Errorf("%w: %w",
+			errConfigReloadFailed, err,
+		)
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadKeepsOriginalConfig(t *testing.T) {
+	tmp := t.TempDir()
+
+	file := filepath.Join(tmp, "config.yaml")
+	err := os.WriteFile(file, []byte(`
+log:
+  level: debug
+
+repositories:
+  org/repo:
+    releases:
+      (v\d+\.\d+\.\d+):
+        assets:
+          app-(.*):
+            destinations:
+              - type: gcp.artifactregistry.generic
+                path: projects/p/locations/l/repositories/r
+                package: app
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New()
+	cfg.file = file
+	cfg.Dir.Jobs = filepath.Join(tmp, "jobs")
+	cfg.Log.Level = "info"
+	cfg.Log.Mode = "prod"
+
+	// the github app is not configured, so the full validation would fail
+	res, err := cfg.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if _, exists := res.Repositories["org/repo"]; !exists {
+		t.Errorf("Reload() repositories = %v, want org/repo", res.Repositories)
+	}
+	if res.Log.Level != "debug" {
+		t.Errorf("Reload() log level = %q, want %q", res.Log.Level, "debug")
+	}
+
+	if cfg.Repositories != nil {
+		t.Errorf("original repositories = %v, want nil", cfg.Repositories)
+	}
+	if cfg.Log.Level != "info" {
+		t.Errorf("original log level = %q, want %q", cfg.Log.Level, "info")
+	}
+	if _, err := os.Stat(cfg.Dir.Jobs); !os.IsNotExist(err) {
+		t.Errorf("jobs directory was created on reload: %v", err)
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(file, []byte(`
+repositories:
+  org/repo:
+    releases:
+      (v\d+\.\d+\.\d+:
+        assets: {}
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New()
+	cfg.file = file
+	cfg.Log.Level = "info"
+	cfg.Log.Mode = "prod"
+
+	if _, err := cfg.Reload(); err == nil {
+		t.Fatal("Reload() error = nil, want an error")
+	}
+}
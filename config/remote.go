@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// RemoteDefaultPath is the default location of the per-repository config
+// within the source repository.
+const RemoteDefaultPath = ".github/artifacts-sync.yaml"
+
+// Remote enables per-repository configuration discovered from the source
+// repository itself.  The discovered rules are restricted to the allowed
+// destinations.
+type Remote struct {
+	AllowedDestinations []*AllowedDestination `yaml:"allowed_destinations" json:"allowed_destinations"`
+	Path                string                `yaml:"path"                 json:"path"`
+}
+
+// AllowedDestination matches the destinations that the per-repository config
+// is permitted to use.  `path` and `package` are regexps that must match the
+// whole value (empty `package` matches any).
+type AllowedDestination struct {
+	Package string `yaml:"package" json:"package"`
+	Path    string `yaml:"path"    json:"path"`
+	Type    string `yaml:"type"    json:"type"`
+
+	pkg  *regexp.Regexp `yaml:"-" json:"-"`
+	path *regexp.Regexp `yaml:"-" json:"-"`
+}
+
+var (
+	errRemoteNoAllowedDestinations           = errors.New("remote config must have at least one allowed destination")
+	errAllowedDestinationInvalidType         = errors.New("invalid allowed destination type")
+	errAllowedDestinationInvalidPathRegexp   = errors.New("invalid allowed destination path regexp")
+	errAllowedDestinationInvalidPackageRegex = errors.New("invalid allowed destination package regexp")
+
+	errRemoteFailedToParse          = errors.New("failed to parse remote config")
+	errRemoteNested                 = errors.New("remote config can not enable remote config")
+	errRemoteDestinationNotAllowed  = errors.New("destination is not allowed for remote config")
+	errRemoteDestinationIsTemplated = errors.New("remote config can not use templates in destination path or package")
+	errRemoteDestinationRetention   = errors.New("remote config can not configure destination retention")
+	errRemoteWorkflowAcceptsForks   = errors.New("remote config can not accept workflow runs from forks")
+)
+
+func (cfg *Remote) Validate() error {
+	if len(cfg.AllowedDestinations) == 0 {
+		return errRemoteNoAllowedDestinations
+	}
+
+	if cfg.Path == "" {
+		cfg.Path = RemoteDefaultPath
+	}
+
+	return nil
+}
+
+func (cfg *AllowedDestination) Validate() error {
+	errs := make([]error, 0)
+
+	allDestinations := destinationTypes
+
+	{ // type
+		if !slices.Contains(allDestinations, cfg.Type) {
+			errs = append(errs, fmt.Errorf("%w: %s (must be one of: %s)",
+				errAllowedDestinationInvalidType, cfg.Type, strings.Join(allDestinations, ","),
+			))
+		}
+	}
+
+	{ // path
+		if re, err := regexp.Compile("^(?:" + cfg.Path + ")$"); err == nil {
+			cfg.path = re
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %s: %w",
+				errAllowedDestinationInvalidPathRegexp, cfg.Path, err,
+			))
+		}
+	}
+
+	{ // package
+		if cfg.Package != "" {
+			if re, err := regexp.Compile("^(?:" + cfg.Package + ")$"); err == nil {
+				cfg.pkg = re
+			} else {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errAllowedDestinationInvalidPackageRegex, cfg.Package, err,
+				))
+			}
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// Allows returns true if the destination matches the allowed one.
+func (cfg *AllowedDestination) Allows(dst *Destination) bool {
+	if cfg.Type != dst.Type || cfg.path == nil || !cfg.path.MatchString(dst.Path) {
+		return false
+	}
+	return cfg.pkg == nil || cfg.pkg.MatchString(dst.Package)
+}
+
+// Allows returns true if any of the allowed destinations matches.
+func (cfg *Remote) Allows(dst *Destination) bool {
+	for _, allowed := range cfg.AllowedDestinations {
+		if allowed.Allows(dst) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses and validates the per-repository config discovered from
+// the source repository.  Unlike the central config, it is not subject to the
+// environment variable expansion and secret references resolution.
+func (cfg *Remote) Parse(bytes []byte) (*Repository, error) {
+	repo := &Repository{}
+	if err := yaml.UnmarshalStrict(bytes, repo); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w",
+			errRemoteFailedToParse, cfg.Path, err,
+		)
+	}
+
+	if repo.Remote != nil {
+		return nil, fmt.Errorf("%w: %s: %w",
+			errRemoteFailedToParse, cfg.Path, errRemoteNested,
+		)
+	}
+
+	if err := validate(repo); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w",
+			errRemoteFailedToParse, cfg.Path, err,
+		)
+	}
+
+	errs := make([]error, 0)
+	for name, workflow := range repo.Workflows {
+		if workflow.AcceptForks {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errRemoteWorkflowAcceptsForks, name,
+			))
+		}
+	}
+	for _, dst := range repo.Destinations() {
+		if strings.Contains(dst.Path, "{{") || strings.Contains(dst.Package, "{{") {
+			errs = append(errs, fmt.Errorf("%w: %s: %s",
+				errRemoteDestinationIsTemplated, dst.Path, dst.Package,
+			))
+			continue
+		}
+		if dst.Retention != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %s",
+				errRemoteDestinationRetention, dst.Path, dst.Package,
+			))
+			continue
+		}
+		if !cfg.Allows(dst) {
+			errs = append(errs, fmt.Errorf("%w: %s: %s: %s",
+				errRemoteDestinationNotAllowed, dst.Type, dst.Path, dst.Package,
+			))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s: %w",
+			errRemoteFailedToParse, cfg.Path, utils.FlattenErrors(errs),
+		)
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRemoteParseRejectsAcceptForks(t *testing.T) {
+	remote := &Remote{
+		AllowedDestinations: []*AllowedDestination{{
+			Type: DestinationGcpArtifactRegistryGeneric,
+			Path: "projects/p/locations/l/repositories/r",
+		}},
+	}
+	if err := validate(remote); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr error
+	}{
+		{
+			name: "no forks",
+			yaml: `
+workflows:
+  release.yaml:
+    artifacts:
+      app-(.*):
+        destinations:
+          - type: gcp.artifactregistry.generic
+            path: projects/p/locations/l/repositories/r
+            package: app
+`,
+		},
+		{
+			name: "accept forks",
+			yaml: `
+workflows:
+  release.yaml:
+    accept_forks: true
+    artifacts:
+      app-(.*):
+        destinations:
+          - type: gcp.artifactregistry.generic
+            path: projects/p/locations/l/repositories/r
+            package: app
+`,
+			wantErr: errRemoteWorkflowAcceptsForks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := remote.Parse([]byte(tt.yaml))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
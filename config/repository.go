@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"regexp"
 
 	"github.com/flashbots/gh-artifacts-sync/utils"
@@ -10,7 +11,10 @@ import (
 
 type Repository struct {
 	Containers map[string]*Container `yaml:"containers" json:"containers"`
+	Maven      map[string]*Maven     `yaml:"maven"      json:"maven"`
+	Npm        map[string]*Npm       `yaml:"npm"        json:"npm"`
 	Releases   map[string]*Release   `yaml:"releases"   json:"releases"`
+	Remote     *Remote               `yaml:"remote"     json:"remote"`
 	Workflows  map[string]*Workflow  `yaml:"workflows"  json:"workflows"`
 }
 
@@ -33,3 +37,51 @@ func (cfg *Repository) Validate() error {
 
 	return utils.FlattenErrors(errs)
 }
+
+// Destinations returns all destinations configured for the repository.
+func (cfg *Repository) Destinations() []*Destination {
+	res := make([]*Destination, 0)
+
+	for _, c := range cfg.Containers {
+		res = append(res, c.Destinations...)
+	}
+	for _, m := range cfg.Maven {
+		res = append(res, m.Destinations...)
+	}
+	for _, n := range cfg.Npm {
+		res = append(res, n.Destinations...)
+	}
+	for _, r := range cfg.Releases {
+		for _, a := range r.Assets {
+			res = append(res, a.Destinations...)
+		}
+	}
+	for _, w := range cfg.Workflows {
+		for _, a := range w.Artifacts {
+			res = append(res, a.Destinations...)
+		}
+	}
+
+	return res
+}
+
+// WithRemote returns a copy of the repository config with the rules of the
+// remote (per-repository) config added.  Rules of the central config take
+// precedence over the remote ones with the same key.
+func (cfg *Repository) WithRemote(remote *Repository) *Repository {
+	return &Repository{
+		Containers: mergeRules(remote.Containers, cfg.Containers),
+		Maven:      mergeRules(remote.Maven, cfg.Maven),
+		Npm:        mergeRules(remote.Npm, cfg.Npm),
+		Releases:   mergeRules(remote.Releases, cfg.Releases),
+		Remote:     cfg.Remote,
+		Workflows:  mergeRules(remote.Workflows, cfg.Workflows),
+	}
+}
+
+func mergeRules[T any](remote, central map[string]T) map[string]T {
+	res := make(map[string]T, len(remote)+len(central))
+	maps.Copy(res, remote)
+	maps.Copy(res, central)
+	return res
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+// Retention limits the versions kept at the (generic) destination.  The
+// version is kept if it satisfies any of the configured rules, and is
+// deleted otherwise.
+type Retention struct {
+	KeepLast      int           `yaml:"keep_last"       json:"keep_last"`
+	KeepNewerThan time.Duration `yaml:"keep_newer_than" json:"keep_newer_than"`
+	KeepSemver    bool          `yaml:"keep_semver"     json:"keep_semver"`
+}
+
+var (
+	errRetentionInvalidKeepLast      = errors.New("invalid retention keep-last count")
+	errRetentionInvalidKeepNewerThan = errors.New("invalid retention keep-newer-than age")
+	errRetentionNoLimits             = errors.New("retention must configure keep_last and/or keep_newer_than")
+)
+
+// semverRelease matches the versions like `1.2.3`, `v1.2.3`, or `v1.2.3+build`
+// (but not the pre-releases like `v1.2.3-rc1`).
+var semverRelease = regexp.MustCompile(`^[vV]?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(\+[0-9A-Za-z.-]+)?$`)
+
+func (cfg *Retention) Validate() error {
+	errs := make([]error, 0)
+
+	{ // keep_last
+		if cfg.KeepLast < 0 {
+			errs = append(errs, fmt.Errorf("%w: %d",
+				errRetentionInvalidKeepLast, cfg.KeepLast,
+			))
+		}
+	}
+
+	{ // keep_newer_than
+		if cfg.KeepNewerThan < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s",
+				errRetentionInvalidKeepNewerThan, cfg.KeepNewerThan,
+			))
+		}
+	}
+
+	if cfg.KeepLast == 0 && cfg.KeepNewerThan == 0 {
+		errs = append(errs, errRetentionNoLimits)
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// Keeps returns true if the version must be kept at the destination.  `idx`
+// is the position of the version in the list of all versions of the package
+// sorted from the newest to the oldest.
+func (cfg *Retention) Keeps(idx int, version string, created time.Time, now time.Time) bool {
+	if idx < cfg.KeepLast {
+		return true
+	}
+	if cfg.KeepNewerThan > 0 && now.Sub(created) < cfg.KeepNewerThan {
+		return true
+	}
+	if cfg.KeepSemver && semverRelease.MatchString(version) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionKeeps(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		retention Retention
+		idx       int
+		version   string
+		age       time.Duration
+		keeps     bool
+	}{
+		{"within keep_last", Retention{KeepLast: 3}, 2, "abc", 365 * 24 * time.Hour, true},
+		{"beyond keep_last", Retention{KeepLast: 3}, 3, "abc", time.Hour, false},
+		{"newer than", Retention{KeepNewerThan: 24 * time.Hour}, 10, "abc", time.Hour, true},
+		{"older than", Retention{KeepNewerThan: 24 * time.Hour}, 10, "abc", 48 * time.Hour, false},
+		{"exactly the age", Retention{KeepNewerThan: 24 * time.Hour}, 10, "abc", 24 * time.Hour, false},
+		{"either rule keeps", Retention{KeepLast: 1, KeepNewerThan: 24 * time.Hour}, 5, "abc", time.Hour, true},
+		{"neither rule keeps", Retention{KeepLast: 1, KeepNewerThan: 24 * time.Hour}, 5, "abc", 48 * time.Hour, false},
+		{"semver release", Retention{KeepLast: 1, KeepSemver: true}, 5, "v1.2.3", 48 * time.Hour, true},
+		{"semver release w/o v", Retention{KeepLast: 1, KeepSemver: true}, 5, "1.2.3", 48 * time.Hour, true},
+		{"semver release w/ build", Retention{KeepLast: 1, KeepSemver: true}, 5, "v1.2.3+abcd", 48 * time.Hour, true},
+		{"semver pre-release", Retention{KeepLast: 1, KeepSemver: true}, 5, "v1.2.3-rc1", 48 * time.Hour, false},
+		{"not semver", Retention{KeepLast: 1, KeepSemver: true}, 5, "v1.2", 48 * time.Hour, false},
+		{"semver w/o keep_semver", Retention{KeepLast: 1}, 5, "v1.2.3", 48 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.retention.Keeps(tt.idx, tt.version, now.Add(-tt.age), now)
+			if got != tt.keeps {
+				t.Errorf("Keeps(%d, %q, -%s) = %v, want %v", tt.idx, tt.version, tt.age, got, tt.keeps)
+			}
+		})
+	}
+}
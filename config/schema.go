@@ -0,0 +1,138 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaEnumsProvider is implemented by the config types that restrict the
+// values of some of their fields to a fixed set.
+type schemaEnumsProvider interface {
+	schemaEnums() map[string][]string
+}
+
+// destinationTypesSupporter is implemented by the config sections that
+// restrict the types of their destinations.
+type destinationTypesSupporter interface {
+	SupportedDestinationTypes() []string
+}
+
+var (
+	typeDuration    = reflect.TypeOf(time.Duration(0))
+	typeDestination = reflect.TypeOf(Destination{})
+)
+
+// Schema returns the JSON Schema of the configuration file.  It is generated
+// from the config types (using their yaml tags), so that it never drifts from
+// them.
+func Schema() map[string]interface{} {
+	schema := schemaOf(reflect.TypeOf(Config{}), nil)
+
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "gh-artifacts-sync configuration"
+
+	return schema
+}
+
+// RepositorySchema returns the JSON Schema of the per-repository config file
+// (see Remote).
+func RepositorySchema() map[string]interface{} {
+	schema := schemaOf(reflect.TypeOf(Repository{}), nil)
+	delete(schema["properties"].(map[string]interface{}), "remote")
+
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "gh-artifacts-sync per-repository configuration"
+
+	return schema
+}
+
+func schemaOf(t reflect.Type, destinationTypes []string) map[string]interface{} {
+	if t == typeDuration {
+		return map[string]interface{}{
+			"type":        "string",
+			"description": "duration (e.g. 30s, 5m, 1h)",
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), destinationTypes)
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Bool: // strings are for `${VAR}` references
+		return map[string]interface{}{"type": []string{"boolean", "string"}}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": []string{"integer", "string"}}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": []string{"number", "string"}}
+
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaOf(t.Elem(), destinationTypes),
+		}
+
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaOf(t.Elem(), destinationTypes),
+		}
+
+	case reflect.Struct:
+		if s, ok := reflect.New(t).Interface().(destinationTypesSupporter); ok {
+			destinationTypes = s.SupportedDestinationTypes()
+		}
+
+		properties := make(map[string]interface{}, t.NumField())
+		for idx := 0; idx < t.NumField(); idx++ {
+			f := t.Field(idx)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			properties[name] = schemaOf(f.Type, destinationTypes)
+		}
+
+		if p, ok := reflect.New(t).Interface().(schemaEnumsProvider); ok {
+			for name, enum := range p.schemaEnums() {
+				if property, ok := properties[name].(map[string]interface{}); ok {
+					property["enum"] = enum
+				}
+			}
+		}
+
+		if t == typeDestination && destinationTypes != nil {
+			properties["type"].(map[string]interface{})["enum"] = destinationTypes
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (cfg *Destination) schemaEnums() map[string][]string {
+	return map[string][]string{
+		"tagless": destinationTaglessModes,
+		"type":    destinationTypes,
+	}
+}
+
+func (cfg *AllowedDestination) schemaEnums() map[string][]string {
+	return map[string][]string{
+		"type": destinationTypes,
+	}
+}
@@ -4,22 +4,47 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 )
 
 type Server struct {
-	ListenAddress string `yaml:"listen_address" json:"listen_address"`
+	ConfigWatchInterval time.Duration `yaml:"config_watch_interval" json:"config_watch_interval"`
+	DedupeDeliveries    int           `yaml:"dedupe_deliveries"     json:"dedupe_deliveries"`
+	ListenAddress       string        `yaml:"listen_address"        json:"listen_address"`
+	RetentionInterval   time.Duration `yaml:"retention_interval"    json:"retention_interval"`
 }
 
 var (
-	errServerInvalidListenAddress = errors.New("invalid server listen address")
+	errServerInvalidConfigWatchInterval = errors.New("invalid config watch interval")
+	errServerInvalidDedupeDeliveries    = errors.New("invalid count of deliveries to dedupe")
+	errServerInvalidListenAddress       = errors.New("invalid server listen address")
+	errServerInvalidRetentionInterval   = errors.New("invalid retention interval")
 )
 
 func (cfg *Server) Validate() error {
+	if cfg.ConfigWatchInterval < 0 {
+		return fmt.Errorf("%w: %s",
+			errServerInvalidConfigWatchInterval, cfg.ConfigWatchInterval,
+		)
+	}
+
+	if cfg.DedupeDeliveries < 0 {
+		return fmt.Errorf("%w: %d",
+			errServerInvalidDedupeDeliveries, cfg.DedupeDeliveries,
+		)
+	}
+
 	if _, err := net.ResolveTCPAddr("tcp", cfg.ListenAddress); err != nil {
 		return fmt.Errorf("%w: %w",
 			errServerInvalidListenAddress, err,
 		)
 	}
 
+	if cfg.RetentionInterval < 0 {
+		return fmt.Errorf("%w: %s",
+			errServerInvalidRetentionInterval, cfg.RetentionInterval,
+		)
+	}
+
 	return nil
 }
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data available to the templated destination fields
+// (`path`, `package`, `version`, and `filename`).
+type TemplateData struct {
+	Digest      string            `json:"digest,omitempty"`
+	Filename    string            `json:"filename,omitempty"`
+	Groups      map[string]string `json:"groups,omitempty"`
+	HeadBranch  string            `json:"head_branch,omitempty"`
+	HeadSHA     string            `json:"head_sha,omitempty"`
+	Package     string            `json:"package,omitempty"`
+	Platform    string            `json:"platform,omitempty"`
+	ReleaseName string            `json:"release_name,omitempty"`
+	ReleaseTag  string            `json:"release_tag,omitempty"`
+	Repo        string            `json:"repo,omitempty"`
+	RepoOwner   string            `json:"repo_owner,omitempty"`
+	RunNumber   int               `json:"run_number,omitempty"`
+	Tag         string            `json:"tag,omitempty"`
+	Version     string            `json:"version,omitempty"`
+}
+
+// NamedGroups returns the named capture groups of the regexp match.
+func NamedGroups(names []string, matches []string) map[string]string {
+	groups := make(map[string]string)
+	for idx, name := range names {
+		if name == "" || idx >= len(matches) {
+			continue
+		}
+		groups[name] = matches[idx]
+	}
+	return groups
+}
+
+var templateFuncs = template.FuncMap{
+	"lower":        strings.ToLower,
+	"regexReplace": regexReplace,
+	"replace":      func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"semver":       semver,
+	"trimPrefix":   func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix":   func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"trunc":        trunc,
+	"upper":        strings.ToUpper,
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+}
+
+func renderTemplate(name, text string, data *TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var res strings.Builder
+	if err := tmpl.Execute(&res, data); err != nil {
+		return "", err
+	}
+
+	return res.String(), nil
+}
+
+// semver normalises the version string by stripping the leading `v` (as in
+// `v1.2.3` -> `1.2.3`).
+func semver(s string) string {
+	if len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && s[1] >= '0' && s[1] <= '9' {
+		return s[1:]
+	}
+	return s
+}
+
+func trunc(n int, s string) string {
+	if n < 0 || len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+func regexReplace(pattern, replacement, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, replacement), nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+var (
+	errVersionInvalidTemplate = errors.New("invalid version template")
+	errVersionEmpty           = errors.New("version rendered to empty string")
+)
+
+// versionGroup is the name of the capture group that is used as version
+// when there is no explicit `version` template configured.
+const versionGroup = "version"
+
+// validateVersionTemplate parses the version template and renders it against
+// placeholder data so that references to undefined fields or capture groups
+// are reported at config load time.
+func validateVersionTemplate(text string, regexps ...*regexp.Regexp) error {
+	if text == "" {
+		return nil
+	}
+
+	data := &TemplateData{Groups: make(map[string]string)}
+	for _, re := range regexps {
+		if re == nil {
+			continue
+		}
+		for _, name := range re.SubexpNames() {
+			if name != "" {
+				data.Groups[name] = "0"
+			}
+		}
+	}
+
+	if _, err := renderTemplate("version", text, data); err != nil {
+		return fmt.Errorf("%w: %s: %w",
+			errVersionInvalidTemplate, text, err,
+		)
+	}
+
+	return nil
+}
+
+// extractVersion returns the version according to the following rules:
+//
+//   - if the template is set, it is rendered against the data;
+//   - otherwise, if the regexp has a capture group named `version`, its
+//     value is used;
+//   - otherwise, the first capture group is used (if there is one);
+//   - otherwise, the fallback is returned.
+func extractVersion(
+	text string,
+	re *regexp.Regexp,
+	matches []string,
+	data *TemplateData,
+	fallback string,
+) (string, error) {
+	if text != "" {
+		version, err := renderTemplate("version", text, data)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %w",
+				errVersionInvalidTemplate, text, err,
+			)
+		}
+		if version == "" {
+			return "", fmt.Errorf("%w: %s",
+				errVersionEmpty, text,
+			)
+		}
+		return version, nil
+	}
+
+	if re != nil {
+		if idx := slices.Index(re.SubexpNames(), versionGroup); idx > 0 && idx < len(matches) {
+			return matches[idx], nil
+		}
+	}
+
+	if len(matches) > 1 {
+		return matches[1], nil
+	}
+
+	return fallback, nil
+}
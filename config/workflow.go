@@ -4,19 +4,25 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 
 	"github.com/flashbots/gh-artifacts-sync/utils"
 )
 
 type Workflow struct {
-	Actors    []string             `yaml:"actors"    json:"actors"`
-	Artifacts map[string]*Artifact `yaml:"artifacts" json:"artifacts"`
+	AcceptForks bool                 `yaml:"accept_forks" json:"accept_forks"`
+	Actors      []string             `yaml:"actors"       json:"actors"`
+	Artifacts   map[string]*Artifact `yaml:"artifacts"    json:"artifacts"`
+	Branches    []string             `yaml:"branches"     json:"branches"`
+	Events      []string             `yaml:"events"       json:"events"`
 
-	actors map[string]struct{} `yaml:"-" json:"-"`
+	actors   map[string]struct{} `yaml:"-" json:"-"`
+	branches []*regexp.Regexp    `yaml:"-" json:"-"`
 }
 
 var (
 	errWorkflowInvalidArtifactRegexp = errors.New("invalid artifact regexp")
+	errWorkflowInvalidBranchRegexp   = errors.New("invalid branch regexp")
 )
 
 func (cfg *Workflow) Validate() error {
@@ -29,6 +35,19 @@ func (cfg *Workflow) Validate() error {
 		}
 	}
 
+	{ // branches
+		cfg.branches = make([]*regexp.Regexp, 0, len(cfg.Branches))
+		for _, regex := range cfg.Branches {
+			if re, err := regexp.Compile(regex); err == nil {
+				cfg.branches = append(cfg.branches, re)
+			} else {
+				errs = append(errs, fmt.Errorf("%w: %s: %w",
+					errWorkflowInvalidBranchRegexp, regex, err,
+				))
+			}
+		}
+	}
+
 	{ // artifacts
 		for regex, a := range cfg.Artifacts {
 			if re, err := regexp.Compile(regex); err == nil {
@@ -37,6 +56,11 @@ func (cfg *Workflow) Validate() error {
 				errs = append(errs, fmt.Errorf("%w: %s: %w",
 					errWorkflowInvalidArtifactRegexp, regex, err,
 				))
+				continue
+			}
+
+			if err := validateVersionTemplate(a.Version, a.regexp); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", regex, err))
 			}
 		}
 	}
@@ -48,3 +72,46 @@ func (cfg *Workflow) HasActor(a string) bool {
 	_, has := cfg.actors[a]
 	return has
 }
+
+// HasBranch returns true if the head branch (or tag) of the workflow run
+// matches any of the configured regexps (or if there are none).
+func (cfg *Workflow) HasBranch(b string) bool {
+	if len(cfg.branches) == 0 {
+		return true
+	}
+	for _, re := range cfg.branches {
+		if re.MatchString(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipReason returns the reason why the workflow run (triggered by the actor
+// with the event, on the head branch, and possibly from a fork) does not pass
+// the filters of the workflow config (or empty string if it does).
+func (cfg *Workflow) SkipReason(actor, branch, event string, fromFork bool) string {
+	if len(cfg.Actors) > 0 && !cfg.HasActor(actor) {
+		return "triggering actor is not in the configured list"
+	}
+
+	if !cfg.HasBranch(branch) {
+		return "head branch does not match the configured ones"
+	}
+
+	if !cfg.HasEvent(event) {
+		return "triggering event is not in the configured list"
+	}
+
+	if !cfg.AcceptForks && fromFork {
+		return "it's from a fork"
+	}
+
+	return ""
+}
+
+// HasEvent returns true if the event that triggered the workflow run is in
+// the configured list (or if it's empty).
+func (cfg *Workflow) HasEvent(e string) bool {
+	return len(cfg.Events) == 0 || slices.Contains(cfg.Events, e)
+}
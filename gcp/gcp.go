@@ -2,11 +2,13 @@ package gcp
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/artifactregistry/v1"
 	"google.golang.org/api/option"
+	"google.golang.org/api/secretmanager/v1"
 )
 
 type Client struct{}
@@ -58,3 +60,44 @@ func (cli *Client) ArtifactRegistryFiles(ctx context.Context) (
 
 	return svc.Projects.Locations.Repositories.Files, nil
 }
+
+func (cli *Client) ArtifactRegistryPackages(ctx context.Context) (
+	*artifactregistry.ProjectsLocationsRepositoriesPackagesService, error,
+) {
+	creds, err := google.FindDefaultCredentials(ctx, artifactregistry.CloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gcp credentials: %w", err)
+	}
+	svc, err := artifactregistry.NewService(ctx, option.WithCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise gcp artifact registry service: %w", err)
+	}
+
+	return svc.Projects.Locations.Repositories.Packages, nil
+}
+
+func (cli *Client) SecretManagerAccess(ctx context.Context, name string) ([]byte, error) {
+	creds, err := google.FindDefaultCredentials(ctx, secretmanager.CloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gcp credentials: %w", err)
+	}
+	svc, err := secretmanager.NewService(ctx, option.WithCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise gcp secret manager service: %w", err)
+	}
+
+	res, err := svc.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access gcp secret: %s: %w", name, err)
+	}
+	if res.Payload == nil {
+		return nil, fmt.Errorf("gcp secret has no payload: %s", name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(res.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode gcp secret: %s: %w", name, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,265 @@
+package job
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+)
+
+const TypeDeleteFromDestinations = "delete-from-destinations"
+
+// DeleteFromDestinations removes the version (generic destinations) or the
+// tags (docker destinations) of the deleted source (release asset, or
+// container version) from the destinations.
+type DeleteFromDestinations struct {
+	Meta *Meta `json:"meta"`
+
+	Container    *config.Container         `json:"container,omitempty"`
+	Destinations []*config.Destination     `json:"destinations"`
+	NotBefore    time.Time                 `json:"not_before"`
+	Package      *github.Package           `json:"package,omitempty"`
+	Release      *github.RepositoryRelease `json:"release,omitempty"`
+	Repository   *github.Repository        `json:"repository"`
+	Tags         []string                  `json:"tags,omitempty"`
+	Template     *config.TemplateData      `json:"template,omitempty"`
+	Version      string                    `json:"version,omitempty"`
+}
+
+func NewDeleteReleaseAsset(
+	release *github.RepositoryRelease,
+	repository *github.Repository,
+	asset *github.ReleaseAsset,
+	version string,
+	destinations []*config.Destination,
+	template *config.TemplateData,
+	notBefore time.Time,
+) *DeleteFromDestinations {
+	var id string
+	if asset != nil &&
+		asset.ID != nil {
+		// ---
+		id = fmt.Sprintf("%s-asset-%d", TypeDeleteFromDestinations, *asset.ID)
+	} else {
+		id = fmt.Sprintf("%s-noid-%d", TypeDeleteFromDestinations, rand.Int64())
+	}
+
+	return &DeleteFromDestinations{
+		Meta: &Meta{
+			ID:   id,
+			Type: TypeDeleteFromDestinations,
+		},
+
+		Destinations: destinations,
+		NotBefore:    notBefore,
+		Release: &github.RepositoryRelease{ // assets are not needed
+			ID:      release.ID,
+			Name:    release.Name,
+			TagName: release.TagName,
+		},
+		Repository: repository,
+		Template:   template,
+		Version:    version,
+	}
+}
+
+func NewDeletePackageVersion(
+	pkg *github.Package,
+	repository *github.Repository,
+	tags []string,
+	container *config.Container,
+	destinations []*config.Destination,
+	notBefore time.Time,
+) *DeleteFromDestinations {
+	var id string
+	if pkg != nil &&
+		pkg.PackageVersion != nil &&
+		pkg.PackageVersion.ID != nil {
+		// ---
+		id = fmt.Sprintf("%s-package-version-%d", TypeDeleteFromDestinations, *pkg.PackageVersion.ID)
+	} else {
+		id = fmt.Sprintf("%s-noid-%d", TypeDeleteFromDestinations, rand.Int64())
+	}
+
+	return &DeleteFromDestinations{
+		Meta: &Meta{
+			ID:   id,
+			Type: TypeDeleteFromDestinations,
+		},
+
+		Container: &config.Container{ // destinations are kept separately
+			Deletion: container.Deletion,
+			SkipTags: container.SkipTags,
+			Tags:     container.Tags,
+		},
+		Destinations: destinations,
+		NotBefore:    notBefore,
+		Package:      pkg,
+		Repository:   repository,
+		Tags:         tags,
+	}
+}
+
+func (j *DeleteFromDestinations) meta() *Meta {
+	if j == nil {
+		return nil
+	}
+	return j.Meta
+}
+
+// HasTag returns true if the tag matches the tag filters of the container
+// (i.e. it could have been synchronised to the destinations).
+func (j *DeleteFromDestinations) HasTag(tag string) bool {
+	if j == nil || j.Container == nil {
+		return false
+	}
+	return j.Container.HasTag(tag)
+}
+
+// IsProtected returns true if the tag must never be deleted from the
+// destinations.
+func (j *DeleteFromDestinations) IsProtected(tag string) bool {
+	if j == nil || j.Container == nil || j.Container.Deletion == nil {
+		return false
+	}
+	return j.Container.Deletion.IsProtected(tag)
+}
+
+func (j *DeleteFromDestinations) GetDestinations() []*config.Destination {
+	return j.Destinations
+}
+
+func (j *DeleteFromDestinations) GetNotBefore() time.Time {
+	if j == nil {
+		return time.Time{}
+	}
+	return j.NotBefore
+}
+
+func (j *DeleteFromDestinations) GetPackageName() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.Name == nil {
+		// ---
+		return ""
+	}
+	return *j.Package.Name
+}
+
+func (j *DeleteFromDestinations) GetPackageOwner() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.Owner == nil ||
+		j.Package.Owner.Login == nil {
+		// ---
+		return j.GetRepoOwner()
+	}
+	return *j.Package.Owner.Login
+}
+
+func (j *DeleteFromDestinations) GetPackageOwnerType() string {
+	if j != nil &&
+		j.Package != nil &&
+		j.Package.Owner != nil &&
+		j.Package.Owner.Type != nil {
+		// ---
+		return *j.Package.Owner.Type
+	}
+	if j != nil &&
+		j.Repository != nil &&
+		j.Repository.Owner != nil &&
+		j.Repository.Owner.Type != nil {
+		// ---
+		return *j.Repository.Owner.Type
+	}
+	return ""
+}
+
+func (j *DeleteFromDestinations) GetPackageVersionID() int64 {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil ||
+		j.Package.PackageVersion.ID == nil {
+		// ---
+		return 0
+	}
+	return *j.Package.PackageVersion.ID
+}
+
+func (j *DeleteFromDestinations) GetReleaseTag() string {
+	if j == nil ||
+		j.Release == nil ||
+		j.Release.TagName == nil {
+		// ---
+		return ""
+	}
+	return *j.Release.TagName
+}
+
+func (j *DeleteFromDestinations) GetRepo() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.Name == nil {
+		// ---
+		return ""
+	}
+	return *j.Repository.Name
+}
+
+func (j *DeleteFromDestinations) GetRepoFullName() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	return *j.Repository.FullName
+}
+
+func (j *DeleteFromDestinations) GetRepoOwner() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.Owner == nil ||
+		j.Repository.Owner.Login == nil {
+		// ---
+		return ""
+	}
+	return *j.Repository.Owner.Login
+}
+
+func (j *DeleteFromDestinations) GetTags() []string {
+	if j == nil {
+		return nil
+	}
+	return j.Tags
+}
+
+func (j *DeleteFromDestinations) GetTemplateData() *config.TemplateData {
+	data := &config.TemplateData{}
+	if j.Template != nil {
+		*data = *j.Template
+	}
+
+	data.Package = j.GetPackageName()
+	data.Repo = j.GetRepo()
+	data.RepoOwner = j.GetRepoOwner()
+	data.Version = j.GetVersion()
+
+	if tags := j.GetTags(); len(tags) > 0 {
+		data.Tag = tags[0]
+		if data.Version == "" {
+			data.Version = tags[0]
+		}
+	}
+
+	return data
+}
+
+func (j *DeleteFromDestinations) GetVersion() string {
+	if j == nil {
+		return ""
+	}
+	return j.Version
+}
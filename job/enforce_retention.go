@@ -0,0 +1,23 @@
+package job
+
+const TypeEnforceRetention = "enforce-retention"
+
+// EnforceRetention deletes the versions that are not retained by the
+// retention rules of the destinations.  There's at most one such job at a
+// time (b/c of the fixed id).
+type EnforceRetention struct {
+	Meta *Meta `json:"meta"`
+}
+
+func NewEnforceRetention() *EnforceRetention {
+	return &EnforceRetention{
+		Meta: &Meta{
+			ID:   TypeEnforceRetention,
+			Type: TypeEnforceRetention,
+		},
+	}
+}
+
+func (j *EnforceRetention) meta() *Meta {
+	return j.Meta
+}
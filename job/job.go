@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/google/renameio/v2"
 )
@@ -14,6 +16,12 @@ type Job interface {
 	meta() *Meta
 }
 
+// Deferred is implemented by the jobs that must not be handled before the
+// certain time.
+type Deferred interface {
+	GetNotBefore() time.Time
+}
+
 type Meta struct {
 	ID   string `json:"id"`
 	Type string `json:"type"`
@@ -41,6 +49,13 @@ func Load(path string) (Job, error) {
 	var job Job
 
 	switch header.Meta.Type {
+	case TypeDeleteFromDestinations:
+		j := &DeleteFromDestinations{}
+		if err := json.Unmarshal(bytes, j); err != nil {
+			return nil, err
+		}
+		job = j
+
 	case TypeDiscoverWorkflowArtifacts:
 		j := &DiscoverWorkflowArtifacts{}
 		if err := json.Unmarshal(bytes, j); err != nil {
@@ -48,6 +63,13 @@ func Load(path string) (Job, error) {
 		}
 		job = j
 
+	case TypeEnforceRetention:
+		j := &EnforceRetention{}
+		if err := json.Unmarshal(bytes, j); err != nil {
+			return nil, err
+		}
+		job = j
+
 	case TypeSyncContainerRegistryPackage:
 		j := &SyncContainerRegistryPackage{}
 		if err := json.Unmarshal(bytes, j); err != nil {
@@ -55,6 +77,20 @@ func Load(path string) (Job, error) {
 		}
 		job = j
 
+	case TypeSyncMavenPackage:
+		j := &SyncMavenPackage{}
+		if err := json.Unmarshal(bytes, j); err != nil {
+			return nil, err
+		}
+		job = j
+
+	case TypeSyncNpmPackage:
+		j := &SyncNpmPackage{}
+		if err := json.Unmarshal(bytes, j); err != nil {
+			return nil, err
+		}
+		job = j
+
 	case TypeSyncReleaseAsset:
 		j := &SyncReleaseAsset{}
 		if err := json.Unmarshal(bytes, j); err != nil {
@@ -106,3 +142,19 @@ func Type(j Job) string {
 func Path(j Job) string {
 	return j.meta().persistedPath
 }
+
+// repoFromApiUrl extracts the owner and the name of the repository from the
+// api url of its resource (e.g. `.../repos/{owner}/{repo}/releases/assets/{id}`).
+// it does not care about the api host (so that it works with github
+// enterprise server as well).
+func repoFromApiUrl(url string) (owner, repo string) {
+	_, path, found := strings.Cut(url, "/repos/")
+	if !found {
+		return "", ""
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) != 5 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
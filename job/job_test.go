@@ -0,0 +1,81 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+)
+
+func TestSaveLoadKeepsDestinationFilters(t *testing.T) {
+	dst := &config.Destination{
+		Type:    config.DestinationGcpArtifactRegistryGeneric,
+		Path:    "projects/p/locations/l/repositories/r",
+		Package: "pkg",
+		Exclude: []string{`\.sha256$`},
+	}
+	if err := dst.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if dst.IncludesFile("foo.sha256") {
+		t.Fatal("foo.sha256 must be excluded before the job is saved")
+	}
+
+	j := NewSyncReleaseAsset(
+		&github.ReleaseAsset{
+			ID:  github.Ptr(int64(42)),
+			URL: github.Ptr("https://api.github.com/repos/o/r/releases/assets/42"),
+		},
+		"v1.0.0",
+		[]*config.Destination{dst},
+		nil,
+	)
+
+	fname, err := Save(j, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, ok := loaded.(*SyncReleaseAsset)
+	if !ok {
+		t.Fatalf("unexpected job type: %T", loaded)
+	}
+	if len(restored.GetDestinations()) != 1 {
+		t.Fatalf("unexpected destinations: %v", restored.GetDestinations())
+	}
+	if restored.GetDestinations()[0].IncludesFile("foo.sha256") {
+		t.Error("foo.sha256 must be excluded after the job is loaded")
+	}
+	if !restored.GetDestinations()[0].IncludesFile("foo.tar.gz") {
+		t.Error("foo.tar.gz must be included after the job is loaded")
+	}
+}
+
+func TestRepoFromApiUrl(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		repo  string
+	}{
+		{"https://api.github.com/repos/org/repo/releases/assets/42", "org", "repo"},
+		{"https://api.github.com/repos/org/repo/actions/artifacts/42", "org", "repo"},
+		{"https://github.example.com/api/v3/repos/org/repo/releases/assets/42", "org", "repo"},
+		{"https://github.example.com/api/v3/repos/org/repo/actions/artifacts/42", "org", "repo"},
+		{"http://10.0.0.1:8080/api/v3/repos/org/repo/releases/assets/42", "org", "repo"},
+		{"https://api.github.com/repos/org/repos/releases/assets/42", "org", "repos"},
+		{"https://api.github.com/repos/org/repo/releases/42", "", ""},
+		{"https://api.github.com/orgs/org/packages/container/app", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		owner, repo := repoFromApiUrl(tt.url)
+		if owner != tt.owner || repo != tt.repo {
+			t.Errorf("repoFromApiUrl(%q) = (%q, %q), want (%q, %q)", tt.url, owner, repo, tt.owner, tt.repo)
+		}
+	}
+}
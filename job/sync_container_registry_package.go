@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/flashbots/gh-artifacts-sync/config"
-	"github.com/flashbots/gh-artifacts-sync/utils"
 	"github.com/google/go-github/v73/github"
 )
 
@@ -18,6 +17,7 @@ type SyncContainerRegistryPackage struct {
 	Destinations []*config.Destination `json:"destinations"`
 	Package      *github.Package       `json:"package"`
 	Repository   *github.Repository    `json:"repository"`
+	Tags         []string              `json:"tags,omitempty"`
 }
 
 func NewSyncContainerRegistryPackage(
@@ -55,16 +55,7 @@ func (j *SyncContainerRegistryPackage) meta() *Meta {
 }
 
 func (j *SyncContainerRegistryPackage) IsTagless() bool {
-	if j == nil ||
-		j.Package == nil ||
-		j.Package.PackageVersion == nil ||
-		j.Package.PackageVersion.ContainerMetadata == nil ||
-		j.Package.PackageVersion.ContainerMetadata.Tag == nil ||
-		j.Package.PackageVersion.ContainerMetadata.Tag.Name == nil {
-		// ---
-		return true
-	}
-	return *j.Package.PackageVersion.ContainerMetadata.Tag.Name == ""
+	return len(j.GetTags()) == 0
 }
 
 func (j *SyncContainerRegistryPackage) GetDestinations() []*config.Destination {
@@ -72,20 +63,14 @@ func (j *SyncContainerRegistryPackage) GetDestinations() []*config.Destination {
 }
 
 func (j *SyncContainerRegistryPackage) GetDestinationReference(dst *config.Destination) string {
-	if j == nil ||
-		j.Package == nil ||
-		j.Package.PackageVersion == nil ||
-		j.Package.PackageVersion.ContainerMetadata == nil ||
-		j.Package.PackageVersion.ContainerMetadata.Tag == nil {
-		// ---
-		return ""
+	var tag string
+	if tags := j.GetTags(); len(tags) > 0 {
+		tag = tags[0]
+	} else {
+		tag = strings.ReplaceAll(j.GetDigest(), ":", "-")
 	}
-
-	tag := utils.MustString(j.Package.PackageVersion.ContainerMetadata.Tag.Name)
 	if tag == "" {
-		tag = strings.ReplaceAll(
-			utils.MustString(j.Package.PackageVersion.ContainerMetadata.Tag.Digest), ":", "-",
-		)
+		return ""
 	}
 	return dst.Path + "/" + dst.Package + ":" + tag
 }
@@ -113,6 +98,35 @@ func (j *SyncContainerRegistryPackage) GetPackageName() string {
 	return *j.Package.Name
 }
 
+func (j *SyncContainerRegistryPackage) GetPackageOwner() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.Owner == nil ||
+		j.Package.Owner.Login == nil {
+		// ---
+		return j.GetRepoOwner()
+	}
+	return *j.Package.Owner.Login
+}
+
+func (j *SyncContainerRegistryPackage) GetPackageOwnerType() string {
+	if j != nil &&
+		j.Package != nil &&
+		j.Package.Owner != nil &&
+		j.Package.Owner.Type != nil {
+		// ---
+		return *j.Package.Owner.Type
+	}
+	if j != nil &&
+		j.Repository != nil &&
+		j.Repository.Owner != nil &&
+		j.Repository.Owner.Type != nil {
+		// ---
+		return *j.Repository.Owner.Type
+	}
+	return ""
+}
+
 func (j *SyncContainerRegistryPackage) GetPackageUrl() string {
 	if j == nil ||
 		j.Package == nil ||
@@ -181,6 +195,35 @@ func (j *SyncContainerRegistryPackage) GetTag() string {
 	return *j.Package.PackageVersion.ContainerMetadata.Tag.Name
 }
 
+func (j *SyncContainerRegistryPackage) GetTemplateData() *config.TemplateData {
+	data := &config.TemplateData{
+		Digest:    j.GetDigest(),
+		Package:   j.GetPackageName(),
+		Repo:      j.GetRepo(),
+		RepoOwner: j.GetRepoOwner(),
+	}
+	if tags := j.GetTags(); len(tags) > 0 {
+		data.Tag = tags[0]
+		data.Version = tags[0]
+	}
+	return data
+}
+
+// GetTags returns the tags to apply at the destinations.  these are the tags
+// resolved via packages api, if any, or the one reported by the event.
+func (j *SyncContainerRegistryPackage) GetTags() []string {
+	if j == nil {
+		return nil
+	}
+	if j.Tags != nil {
+		return j.Tags
+	}
+	if tag := j.GetTag(); tag != "" {
+		return []string{tag}
+	}
+	return nil
+}
+
 func (j *SyncContainerRegistryPackage) GetVersionID() int64 {
 	if j == nil ||
 		j.Package == nil ||
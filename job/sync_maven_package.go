@@ -0,0 +1,147 @@
+package job
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+)
+
+const TypeSyncMavenPackage = "sync-maven-package"
+
+type SyncMavenPackage struct {
+	Meta *Meta `json:"meta"`
+
+	Destinations []*config.Destination `json:"destinations"`
+	Package      *github.Package       `json:"package"`
+	Repository   *github.Repository    `json:"repository"`
+}
+
+func NewSyncMavenPackage(
+	package_ *github.Package,
+	repository *github.Repository,
+	destinations []*config.Destination,
+) *SyncMavenPackage {
+	var id string
+	if package_ != nil &&
+		package_.PackageVersion != nil &&
+		package_.PackageVersion.ID != nil {
+		// ---
+		id = fmt.Sprintf("%s-%d", TypeSyncMavenPackage, *package_.PackageVersion.ID)
+	} else {
+		id = fmt.Sprintf("%s-noid-%d", TypeSyncMavenPackage, rand.Int64())
+	}
+
+	return &SyncMavenPackage{
+		Meta: &Meta{
+			ID:   id,
+			Type: TypeSyncMavenPackage,
+		},
+
+		Destinations: destinations,
+		Package:      package_,
+		Repository:   repository,
+	}
+}
+
+func (j *SyncMavenPackage) meta() *Meta {
+	if j == nil {
+		return nil
+	}
+	return j.Meta
+}
+
+func (j *SyncMavenPackage) GetDestinations() []*config.Destination {
+	return j.Destinations
+}
+
+func (j *SyncMavenPackage) GetFiles() []*github.PackageFile {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil {
+		// ---
+		return nil
+	}
+	return j.Package.PackageVersion.PackageFiles
+}
+
+func (j *SyncMavenPackage) GetPackageName() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.Name == nil {
+		// ---
+		return ""
+	}
+	return *j.Package.Name
+}
+
+func (j *SyncMavenPackage) GetRepo() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	parts := strings.Split(*j.Repository.FullName, "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func (j *SyncMavenPackage) GetRepoFullName() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	return *j.Repository.FullName
+}
+
+func (j *SyncMavenPackage) GetRepoOwner() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	parts := strings.Split(*j.Repository.FullName, "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+func (j *SyncMavenPackage) GetTemplateData() *config.TemplateData {
+	return &config.TemplateData{
+		Package:   j.GetPackageName(),
+		Repo:      j.GetRepo(),
+		RepoOwner: j.GetRepoOwner(),
+		Version:   j.GetVersion(),
+	}
+}
+
+func (j *SyncMavenPackage) GetVersion() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil ||
+		j.Package.PackageVersion.Version == nil {
+		// ---
+		return ""
+	}
+	return *j.Package.PackageVersion.Version
+}
+
+func (j *SyncMavenPackage) GetVersionID() int64 {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil ||
+		j.Package.PackageVersion.ID == nil {
+		// ---
+		return 0
+	}
+	return *j.Package.PackageVersion.ID
+}
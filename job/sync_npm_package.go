@@ -0,0 +1,183 @@
+package job
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+)
+
+const TypeSyncNpmPackage = "sync-npm-package"
+
+type SyncNpmPackage struct {
+	Meta *Meta `json:"meta"`
+
+	Destinations []*config.Destination `json:"destinations"`
+	Package      *github.Package       `json:"package"`
+	Repository   *github.Repository    `json:"repository"`
+}
+
+func NewSyncNpmPackage(
+	package_ *github.Package,
+	repository *github.Repository,
+	destinations []*config.Destination,
+) *SyncNpmPackage {
+	var id string
+	if package_ != nil &&
+		package_.PackageVersion != nil &&
+		package_.PackageVersion.ID != nil {
+		// ---
+		id = fmt.Sprintf("%s-%d", TypeSyncNpmPackage, *package_.PackageVersion.ID)
+	} else {
+		id = fmt.Sprintf("%s-noid-%d", TypeSyncNpmPackage, rand.Int64())
+	}
+
+	return &SyncNpmPackage{
+		Meta: &Meta{
+			ID:   id,
+			Type: TypeSyncNpmPackage,
+		},
+
+		Destinations: destinations,
+		Package:      package_,
+		Repository:   repository,
+	}
+}
+
+func (j *SyncNpmPackage) meta() *Meta {
+	if j == nil {
+		return nil
+	}
+	return j.Meta
+}
+
+func (j *SyncNpmPackage) GetDestinations() []*config.Destination {
+	return j.Destinations
+}
+
+func (j *SyncNpmPackage) GetFiles() []*github.PackageFile {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil {
+		// ---
+		return nil
+	}
+	return j.Package.PackageVersion.PackageFiles
+}
+
+func (j *SyncNpmPackage) GetPackageName() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.Name == nil {
+		// ---
+		return ""
+	}
+	return *j.Package.Name
+}
+
+func (j *SyncNpmPackage) GetRepo() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	parts := strings.Split(*j.Repository.FullName, "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func (j *SyncNpmPackage) GetRepoFullName() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	return *j.Repository.FullName
+}
+
+func (j *SyncNpmPackage) GetRepoOwner() string {
+	if j == nil ||
+		j.Repository == nil ||
+		j.Repository.FullName == nil {
+		// ---
+		return ""
+	}
+	parts := strings.Split(*j.Repository.FullName, "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+func (j *SyncNpmPackage) GetTemplateData() *config.TemplateData {
+	return &config.TemplateData{
+		Package:   j.GetPackageName(),
+		Repo:      j.GetRepo(),
+		RepoOwner: j.GetRepoOwner(),
+		Version:   j.GetVersion(),
+	}
+}
+
+func (j *SyncNpmPackage) GetVersion() string {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil ||
+		j.Package.PackageVersion.Version == nil {
+		// ---
+		return ""
+	}
+	return *j.Package.PackageVersion.Version
+}
+
+func (j *SyncNpmPackage) GetVersionID() int64 {
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil ||
+		j.Package.PackageVersion.ID == nil {
+		// ---
+		return 0
+	}
+	return *j.Package.PackageVersion.ID
+}
+
+// GetNpmName returns the (scoped) name of the package in npm registry.
+func (j *SyncNpmPackage) GetNpmName() string {
+	if j != nil &&
+		j.Package != nil &&
+		j.Package.PackageVersion != nil &&
+		j.Package.PackageVersion.NPMMetadata != nil &&
+		j.Package.PackageVersion.NPMMetadata.Name != nil {
+		// ---
+		return *j.Package.PackageVersion.NPMMetadata.Name
+	}
+	if j.GetPackageName() == "" {
+		return ""
+	}
+	return "@" + j.GetRepoOwner() + "/" + j.GetPackageName()
+}
+
+// GetTarballUrl returns the download url of the package tarball.
+func (j *SyncNpmPackage) GetTarballUrl() string {
+	for _, f := range j.GetFiles() {
+		if f == nil || f.DownloadURL == nil || f.Name == nil {
+			continue
+		}
+		if strings.HasSuffix(*f.Name, ".tgz") {
+			return *f.DownloadURL
+		}
+	}
+	if j == nil ||
+		j.Package == nil ||
+		j.Package.PackageVersion == nil ||
+		j.Package.PackageVersion.NPMMetadata == nil {
+		// ---
+		return ""
+	}
+	return j.Package.PackageVersion.NPMMetadata.Dist["tarball"]
+}
@@ -3,7 +3,6 @@ package job
 import (
 	"fmt"
 	"math/rand/v2"
-	"strings"
 
 	"github.com/flashbots/gh-artifacts-sync/config"
 	"github.com/google/go-github/v73/github"
@@ -16,6 +15,7 @@ type SyncReleaseAsset struct {
 
 	Asset        *github.ReleaseAsset  `json:"asset"`
 	Destinations []*config.Destination `json:"destinations"`
+	Template     *config.TemplateData  `json:"template,omitempty"`
 	Version      string                `json:"version"`
 }
 
@@ -23,6 +23,7 @@ func NewSyncReleaseAsset(
 	asset *github.ReleaseAsset,
 	version string,
 	destinations []*config.Destination,
+	template *config.TemplateData,
 ) *SyncReleaseAsset {
 	var id string
 	if asset != nil &&
@@ -41,6 +42,7 @@ func NewSyncReleaseAsset(
 
 		Asset:        asset,
 		Destinations: destinations,
+		Template:     template,
 		Version:      version,
 	}
 }
@@ -83,14 +85,8 @@ func (j *SyncReleaseAsset) GetRepo() string {
 		// ---
 		return ""
 	}
-	parts := strings.Split(
-		strings.TrimPrefix(*j.Asset.URL, "https://api.github.com/repos/"),
-		"/",
-	)
-	if len(parts) != 5 {
-		return ""
-	}
-	return parts[1]
+	_, repo := repoFromApiUrl(*j.Asset.URL)
+	return repo
 }
 
 func (j *SyncReleaseAsset) GetRepoOwner() string {
@@ -100,14 +96,21 @@ func (j *SyncReleaseAsset) GetRepoOwner() string {
 		// ---
 		return ""
 	}
-	parts := strings.Split(
-		strings.TrimPrefix(*j.Asset.URL, "https://api.github.com/repos/"),
-		"/",
-	)
-	if len(parts) != 5 {
-		return ""
+	owner, _ := repoFromApiUrl(*j.Asset.URL)
+	return owner
+}
+
+func (j *SyncReleaseAsset) GetTemplateData() *config.TemplateData {
+	data := &config.TemplateData{}
+	if j.Template != nil {
+		*data = *j.Template
 	}
-	return parts[0]
+
+	data.Repo = j.GetRepo()
+	data.RepoOwner = j.GetRepoOwner()
+	data.Version = j.GetVersion()
+
+	return data
 }
 
 func (j *SyncReleaseAsset) GetVersion() string {
@@ -3,7 +3,6 @@ package job
 import (
 	"fmt"
 	"math/rand/v2"
-	"strings"
 
 	"github.com/flashbots/gh-artifacts-sync/config"
 	"github.com/google/go-github/v73/github"
@@ -17,6 +16,7 @@ type SyncWorkflowArtifact struct {
 	Artifact     *github.Artifact      `json:"artifact"`
 	Version      string                `json:"version"`
 	Destinations []*config.Destination `json:"destinations"`
+	Template     *config.TemplateData  `json:"template,omitempty"`
 	WorkflowRun  *github.WorkflowRun   `json:"workflow_run"`
 }
 
@@ -25,6 +25,7 @@ func NewSyncWorkflowArtifact(
 	version string,
 	destinations []*config.Destination,
 	workflowRun *github.WorkflowRun,
+	template *config.TemplateData,
 ) *SyncWorkflowArtifact {
 	var id string
 	if artifact != nil &&
@@ -45,6 +46,7 @@ func NewSyncWorkflowArtifact(
 
 		Artifact:     artifact,
 		Destinations: destinations,
+		Template:     template,
 		Version:      version,
 		WorkflowRun:  workflowRun,
 	}
@@ -86,14 +88,11 @@ func (j *SyncWorkflowArtifact) GetRepoFullName() string {
 		j.Artifact.URL == nil {
 		return ""
 	}
-	parts := strings.Split(
-		strings.TrimPrefix(*j.Artifact.URL, "https://api.github.com/repos/"),
-		"/",
-	)
-	if len(parts) != 5 {
+	owner, repo := repoFromApiUrl(*j.Artifact.URL)
+	if owner == "" {
 		return ""
 	}
-	return parts[0] + "/" + parts[1]
+	return owner + "/" + repo
 }
 
 func (j *SyncWorkflowArtifact) GetRepo() string {
@@ -102,14 +101,8 @@ func (j *SyncWorkflowArtifact) GetRepo() string {
 		j.Artifact.URL == nil {
 		return ""
 	}
-	parts := strings.Split(
-		strings.TrimPrefix(*j.Artifact.URL, "https://api.github.com/repos/"),
-		"/",
-	)
-	if len(parts) != 5 {
-		return ""
-	}
-	return parts[1]
+	_, repo := repoFromApiUrl(*j.Artifact.URL)
+	return repo
 }
 
 func (j *SyncWorkflowArtifact) GetRepoOwner() string {
@@ -118,14 +111,27 @@ func (j *SyncWorkflowArtifact) GetRepoOwner() string {
 		j.Artifact.URL == nil {
 		return ""
 	}
-	parts := strings.Split(
-		strings.TrimPrefix(*j.Artifact.URL, "https://api.github.com/repos/"),
-		"/",
-	)
-	if len(parts) != 5 {
-		return ""
+	owner, _ := repoFromApiUrl(*j.Artifact.URL)
+	return owner
+}
+
+func (j *SyncWorkflowArtifact) GetTemplateData() *config.TemplateData {
+	data := &config.TemplateData{}
+	if j.Template != nil {
+		*data = *j.Template
 	}
-	return parts[0]
+
+	data.Repo = j.GetRepo()
+	data.RepoOwner = j.GetRepoOwner()
+	data.Version = j.GetVersion()
+
+	if j.WorkflowRun != nil {
+		data.HeadBranch = j.WorkflowRun.GetHeadBranch()
+		data.HeadSHA = j.WorkflowRun.GetHeadSHA()
+		data.RunNumber = j.WorkflowRun.GetRunNumber()
+	}
+
+	return data
 }
 
 func (j *SyncWorkflowArtifact) GetURL() string {
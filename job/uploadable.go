@@ -4,10 +4,12 @@ import "github.com/flashbots/gh-artifacts-sync/config"
 
 type Uploadable interface {
 	GetDestinations() []*config.Destination
+	GetTemplateData() *config.TemplateData
 }
 
 type UploadableFile interface {
 	GetDestinations() []*config.Destination
+	GetTemplateData() *config.TemplateData
 	GetVersion() string
 }
 
@@ -15,5 +17,15 @@ type UploadableContainer interface {
 	IsTagless() bool
 	GetDestinations() []*config.Destination
 	GetDestinationReference(*config.Destination) string
+	GetDigest() string
+	GetPackageUrl() string
+	GetRepoFullName() string
 	GetTag() string
+	GetTags() []string
+}
+
+type UploadableNpmPackage interface {
+	GetDestinations() []*config.Destination
+	GetNpmName() string
+	GetVersion() string
 }
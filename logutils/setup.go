@@ -15,8 +15,13 @@ var (
 	errLoggerFailedToBuild = errors.New("failed to build the logger")
 	errLoggerInvalidLevel  = errors.New("invalid log-level")
 	errLoggerInvalidMode   = errors.New("invalid log-mode")
+	errLoggerNotSetup      = errors.New("logger is not setup")
 )
 
+// level is the (atomic) level of the logger built by NewLogger, so that it
+// can be adjusted at runtime.
+var level *zap.AtomicLevel
+
 func NewLogger(cfg *config.Log) (
 	*zap.Logger, error,
 ) {
@@ -41,6 +46,7 @@ func NewLogger(cfg *config.Log) (
 		)
 	}
 	config.Level = logLevel
+	level = &logLevel
 
 	l, err := config.Build()
 	if err != nil {
@@ -51,3 +57,20 @@ func NewLogger(cfg *config.Log) (
 
 	return l, nil
 }
+
+// SetLevel changes the level of the logger built by NewLogger.
+func SetLevel(l string) error {
+	if level == nil {
+		return errLoggerNotSetup
+	}
+
+	logLevel, err := zap.ParseAtomicLevel(l)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w",
+			errLoggerInvalidLevel, l, err,
+		)
+	}
+	level.SetLevel(logLevel.Level())
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	otelapi "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
@@ -17,9 +18,21 @@ var (
 	meter otelapi.Meter
 )
 
+// the counters are no-op until Setup is called
+var (
+	DockerBlobBytes   otelapi.Int64Counter = noop.Int64Counter{}
+	DockerBlobs       otelapi.Int64Counter = noop.Int64Counter{}
+	WebhookDeliveries otelapi.Int64Counter = noop.Int64Counter{}
+)
+
+// Setup registers the prometheus exporter in the default registry, therefore
+// it must be called only once per process.
 func Setup(ctx context.Context) error {
 	for _, setup := range []func(context.Context) error{
 		setupMeter, // must come first
+		setupDockerBlobBytes,
+		setupDockerBlobs,
+		setupWebhookDeliveries,
 	} {
 		if err := setup(ctx); err != nil {
 			return err
@@ -52,3 +65,37 @@ func setupMeter(ctx context.Context) error {
 
 	return nil
 }
+
+func setupDockerBlobBytes(ctx context.Context) error {
+	m, err := meter.Int64Counter("docker_blob_bytes",
+		otelapi.WithDescription("bytes of container blobs pushed to the destinations (by outcome: transferred, existing, mounted)"),
+		otelapi.WithUnit("By"),
+	)
+	if err != nil {
+		return err
+	}
+	DockerBlobBytes = m
+	return nil
+}
+
+func setupDockerBlobs(ctx context.Context) error {
+	m, err := meter.Int64Counter("docker_blobs",
+		otelapi.WithDescription("count of container blobs pushed to the destinations (by outcome: transferred, existing, mounted)"),
+	)
+	if err != nil {
+		return err
+	}
+	DockerBlobs = m
+	return nil
+}
+
+func setupWebhookDeliveries(ctx context.Context) error {
+	m, err := meter.Int64Counter("webhook_deliveries",
+		otelapi.WithDescription("count of webhook deliveries (by outcome: valid, invalid; and by the secret that validated them)"),
+	)
+	if err != nil {
+		return err
+	}
+	WebhookDeliveries = m
+	return nil
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"github.com/google/go-github/v73/github"
+	"github.com/google/renameio/v2"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// catchUpInitialLookback is how far back to look for the missed deliveries
+	// when there's no persisted cursor yet (github keeps them for 3 days).
+	catchUpInitialLookback = 24 * time.Hour
+
+	// catchUpOverlap is subtracted from the cursor, so that the deliveries
+	// that were in-flight during the previous catch-up are checked again.
+	catchUpOverlap = 5 * time.Minute
+)
+
+type catchUpCursor struct {
+	Last time.Time `json:"last"`
+}
+
+func (s *Server) catchUpCursorFile() string {
+	return filepath.Join(s.cfg.Dir.State, "catch-up-cursor.json")
+}
+
+// catchUp lists the recent deliveries of the github app, and processes the
+// ones that neither were delivered successfully, nor were processed by us
+// (via webhook, or by the previous catch-up).
+func (s *Server) catchUp(ctx context.Context) {
+	l := logutils.LoggerFromContext(ctx)
+
+	cursor := &catchUpCursor{}
+	{ // load cursor
+		bytes, err := os.ReadFile(s.catchUpCursorFile())
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			cursor.Last = time.Now().Add(-catchUpInitialLookback)
+		case err != nil:
+			l.Error("Failed to read catch-up cursor", zap.Error(err))
+			return
+		default:
+			if err := json.Unmarshal(bytes, cursor); err != nil {
+				l.Error("Failed to parse catch-up cursor", zap.Error(err))
+				return
+			}
+		}
+	}
+
+	start := time.Now()
+	since := cursor.Last.Add(-catchUpOverlap)
+
+	missed, err := s.catchUpListMissed(ctx, since)
+	if err != nil {
+		l.Error("Failed to list github app hook deliveries", zap.Error(err))
+		return
+	}
+
+	processed := 0
+	errs := make([]error, 0)
+	for _, hd := range missed {
+		l := l.With(
+			zap.String("delivery", hd.GetGUID()),
+			zap.String("event", hd.GetEvent()),
+			zap.Int("status_code", hd.GetStatusCode()),
+		)
+
+		if err := s.catchUpProcess(logutils.ContextWithLogger(ctx, l), hd); err != nil {
+			l.Error("Failed to catch up with missed webhook delivery", zap.Error(err))
+			noRetryErr := &utils.NonRetryableError{}
+			if !errors.As(err, &noRetryErr) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		processed++
+	}
+
+	if len(missed) > 0 {
+		l.Info("Caught up with missed webhook deliveries",
+			zap.Int("missed", len(missed)),
+			zap.Int("processed", processed),
+		)
+	}
+
+	if len(errs) > 0 {
+		return // will retry on the next catch-up
+	}
+
+	bytes, err := json.Marshal(&catchUpCursor{Last: start})
+	if err != nil {
+		l.Error("Failed to persist catch-up cursor", zap.Error(err))
+		return
+	}
+	if err := renameio.WriteFile(s.catchUpCursorFile(), bytes, 0640); err != nil {
+		l.Error("Failed to persist catch-up cursor", zap.Error(err))
+	}
+}
+
+// catchUpListMissed returns the latest attempts of the deliveries (made since
+// the given time) that were not successful, oldest first.
+func (s *Server) catchUpListMissed(ctx context.Context, since time.Time) ([]*github.HookDelivery, error) {
+	latest := make(map[string]*github.HookDelivery)
+	succeeded := make(map[string]bool)
+
+	opts := &github.ListCursorOptions{PerPage: 100}
+	for {
+		var res *github.Response
+		deliveries, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) ([]*github.HookDelivery, error) {
+			deliveries, _res, err := s.githubApp.Apps.ListHookDeliveries(ctx, opts)
+			res = _res
+			return deliveries, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		done := res.Cursor == ""
+		for _, hd := range deliveries {
+			if hd.GetDeliveredAt().Before(since) {
+				done = true // deliveries are listed newest first
+				continue
+			}
+			if id := s.cfg.Github.App.InstallationID; id != 0 && hd.GetInstallationID() != id {
+				continue
+			}
+			guid := hd.GetGUID()
+			if code := hd.GetStatusCode(); code >= 200 && code < 300 {
+				succeeded[guid] = true
+			}
+			if prev, known := latest[guid]; !known || hd.GetDeliveredAt().After(prev.GetDeliveredAt().Time) {
+				latest[guid] = hd
+			}
+		}
+
+		if done {
+			break
+		}
+		opts.Cursor = res.Cursor
+	}
+
+	missed := make([]*github.HookDelivery, 0)
+	for guid, hd := range latest {
+		if succeeded[guid] || s.deliveries.Seen(guid) {
+			continue
+		}
+		missed = append(missed, hd)
+	}
+	slices.SortFunc(missed, func(a, b *github.HookDelivery) int {
+		return a.GetDeliveredAt().Compare(b.GetDeliveredAt().Time)
+	})
+
+	return missed, nil
+}
+
+// catchUpProcess fetches the payload of the missed delivery and processes it
+// the same way as the webhook does.
+func (s *Server) catchUpProcess(ctx context.Context, hd *github.HookDelivery) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	full, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) (*github.HookDelivery, error) {
+		full, _, err := s.githubApp.Apps.GetHookDelivery(ctx, hd.GetID())
+		return full, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get hook delivery: %w", err)
+	}
+	if full.Request == nil || full.Request.RawPayload == nil {
+		return utils.DoNotRetry(fmt.Errorf("hook delivery has no payload: %d", hd.GetID()))
+	}
+
+	d := &Delivery{
+		ID:         hd.GetGUID(),
+		Event:      hd.GetEvent(),
+		Headers:    full.Request.Headers,
+		ReceivedAt: time.Now().UTC(),
+		Payload:    *full.Request.RawPayload,
+	}
+
+	if s.cfg.Dir.Deliveries != "" {
+		if fname, err := s.archiveDelivery(d); err == nil {
+			l.Debug("Archived webhook delivery",
+				zap.String("file", fname),
+			)
+		} else {
+			l.Warn("Failed to archive webhook delivery",
+				zap.Error(err),
+			)
+		}
+	}
+
+	event, err := github.ParseWebHook(d.Event, d.Payload)
+	if err != nil {
+		return utils.DoNotRetry(fmt.Errorf("failed to parse webhook event: %w", err))
+	}
+
+	if err := s.processEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if err := s.deliveries.Record(d.ID); err != nil {
+		l.Warn("Failed to record webhook delivery",
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
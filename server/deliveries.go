@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/renameio/v2"
+)
+
+// deliveries remembers the ids of the most recent webhook deliveries, so that
+// the redelivered ones are not processed again.  the ids are appended to the
+// file, that is compacted once it grows twice as big as the limit.
+type deliveries struct {
+	file  string
+	limit int
+
+	mx     sync.Mutex
+	ids    []string
+	lines  int
+	recent map[string]struct{}
+}
+
+func newDeliveries(file string, limit int) (*deliveries, error) {
+	d := &deliveries{
+		file:   file,
+		limit:  limit,
+		ids:    make([]string, 0, limit),
+		recent: make(map[string]struct{}, limit),
+	}
+
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open deliveries file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			d.remember(id)
+		}
+		d.lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deliveries file: %w", err)
+	}
+
+	return d, nil
+}
+
+// Seen returns true if the delivery was already recorded.
+func (d *deliveries) Seen(id string) bool {
+	if d == nil || id == "" {
+		return false
+	}
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	_, seen := d.recent[id]
+	return seen
+}
+
+// Record remembers the delivery and persists it.
+func (d *deliveries) Record(id string) error {
+	if d == nil || id == "" {
+		return nil
+	}
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	if _, seen := d.recent[id]; seen {
+		return nil
+	}
+	d.remember(id)
+
+	if d.lines >= 2*d.limit {
+		return d.compact()
+	}
+
+	f, err := os.OpenFile(d.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open deliveries file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(id + "\n"); err != nil {
+		return fmt.Errorf("failed to write deliveries file: %w", err)
+	}
+	d.lines++
+
+	return nil
+}
+
+func (d *deliveries) remember(id string) {
+	if _, seen := d.recent[id]; seen {
+		return
+	}
+	d.ids = append(d.ids, id)
+	d.recent[id] = struct{}{}
+	if len(d.ids) > d.limit {
+		delete(d.recent, d.ids[0])
+		d.ids = d.ids[1:]
+	}
+}
+
+func (d *deliveries) compact() error {
+	var sb strings.Builder
+	for _, id := range d.ids {
+		sb.WriteString(id)
+		sb.WriteString("\n")
+	}
+
+	if err := renameio.WriteFile(d.file, []byte(sb.String()), 0640); err != nil {
+		return fmt.Errorf("failed to compact deliveries file: %w", err)
+	}
+	d.lines = len(d.ids)
+
+	// re-allocate so that the dropped ids can be garbage-collected
+	d.ids = append(make([]string, 0, d.limit), d.ids...)
+
+	return nil
+}
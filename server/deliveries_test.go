@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeliveries(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deliveries")
+
+	d, err := newDeliveries(file, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"a", "b", "a", "c", "d"} {
+		if err := d.Record(id); err != nil {
+			t.Fatalf("Record(%q) error = %v", id, err)
+		}
+	}
+
+	tests := []struct {
+		id   string
+		seen bool
+	}{
+		{"a", false}, // evicted by `d`
+		{"b", true},
+		{"c", true},
+		{"d", true},
+		{"e", false},
+		{"", false},
+	}
+
+	check := func(d *deliveries) {
+		t.Helper()
+		for _, tt := range tests {
+			if got := d.Seen(tt.id); got != tt.seen {
+				t.Errorf("Seen(%q) = %v, want %v", tt.id, got, tt.seen)
+			}
+		}
+	}
+
+	check(d)
+
+	// the ids survive the restart
+	d, err = newDeliveries(file, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(d)
+}
+
+func TestDeliveriesCompaction(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deliveries")
+
+	d, err := newDeliveries(file, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx := range 10 {
+		if err := d.Record(fmt.Sprintf("id-%d", idx)); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines > 2*2 {
+			t.Fatalf("deliveries file has %d lines after %d records, want at most %d", lines, idx+1, 2*2)
+		}
+	}
+
+	d, err = newDeliveries(file, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx := range 10 {
+		id := fmt.Sprintf("id-%d", idx)
+		if got, want := d.Seen(id), idx >= 8; got != want {
+			t.Errorf("Seen(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestDeliveriesNil(t *testing.T) {
+	var d *deliveries
+	if d.Seen("a") {
+		t.Error("Seen() on nil deliveries = true, want false")
+	}
+	if err := d.Record("a"); err != nil {
+		t.Errorf("Record() on nil deliveries error = %v", err)
+	}
+}
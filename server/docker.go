@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/flashbots/gh-artifacts-sync/config"
 	"github.com/flashbots/gh-artifacts-sync/job"
@@ -217,6 +218,40 @@ func (s *Server) dockerPrepareImage(
 		return nil, nil, nil, utils.FlattenErrors(errs)
 	}
 
+	syncedAt := time.Now()
+
+	if !dst.Mutate.IsEmpty() { // mutate (changes the digests)
+		attestations := make(map[string]struct{})
+		if indexManifest != nil {
+			for _, desc := range indexManifest.Manifests {
+				if desc.Annotations["vnd.docker.reference.type"] == "attestation-manifest" {
+					attestations[desc.Digest.String()] = struct{}{}
+				}
+			}
+		}
+
+		for originalDigest, c := range containers {
+			if _, isAttestation := attestations[originalDigest]; isAttestation {
+				continue
+			}
+
+			annotations := s.dockerMutationAnnotations(j, dst.Mutate, originalDigest, syncedAt)
+			if err := s.dockerMutateContainer(c, annotations, dst.Mutate); err != nil {
+				l.Error("Failed to mutate container",
+					zap.Error(err),
+					zap.String("digest", originalDigest),
+				)
+				errs = append(errs, err)
+				return nil, nil, nil, utils.FlattenErrors(errs)
+			}
+
+			l.Debug("Mutated container",
+				zap.String("original_digest", originalDigest),
+				zap.String("digest", c.digest.String()),
+			)
+		}
+	}
+
 	var ref crname.Reference
 	{ // get remote reference
 		reference := j.GetDestinationReference(dst)
@@ -266,9 +301,102 @@ func (s *Server) dockerPrepareImage(
 			})
 		}
 	}
+
+	if !dst.Mutate.IsEmpty() { // mutate the index (changes the digest)
+		annotations := s.dockerMutationAnnotations(j, dst.Mutate, j.GetDigest(), syncedAt)
+		_index, err := s.dockerMutateIndex(index, annotations)
+		if err != nil {
+			l.Error("Failed to mutate container index", zap.Error(err))
+			errs = append(errs, err)
+			return nil, nil, nil, utils.FlattenErrors(errs)
+		}
+		index = _index
+	}
+
 	return ref, nil, index, utils.FlattenErrors(errs)
 }
 
+// dockerDigestReference re-targets the reference to the digest of the
+// prepared image (or index).  the digest is the one computed for the
+// re-assembled image, and it might differ from the one at the source.
+func (s *Server) dockerDigestReference(
+	ref crname.Reference,
+	image cr.Image,
+	index cr.ImageIndex,
+) (crname.Reference, error) {
+	var (
+		digest cr.Hash
+		err    error
+	)
+
+	switch {
+	case image != nil:
+		digest, err = image.Digest()
+	case index != nil:
+		digest, err = index.Digest()
+	default:
+		return nil, fmt.Errorf("nothing to compute the digest of: %s",
+			ref.Name(),
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the digest: %s: %w",
+			ref.Name(), err,
+		)
+	}
+
+	return crname.NewDigest(ref.Context().Name() + "@" + digest.String())
+}
+
+func (s *Server) dockerTagRemote(
+	ctx context.Context,
+	ref crname.Reference,
+	tags []string,
+	image cr.Image,
+	index cr.ImageIndex,
+	auth crauthn.Authenticator,
+) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	var taggable crremote.Taggable
+	switch {
+	case image != nil:
+		taggable = image
+	case index != nil:
+		taggable = index
+	default:
+		return nil
+	}
+
+	errs := make([]error, 0)
+	for _, _tag := range tags {
+		tag := ref.Context().Tag(_tag)
+
+		l.Debug("Tagging container image",
+			zap.String("destination_extra_reference", tag.String()),
+		)
+
+		if err := crremote.Tag(tag, taggable, crremote.WithAuth(auth)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to tag container image: %s: %w",
+				tag.String(), err,
+			))
+			continue
+		}
+
+		if index != nil {
+			if err := s.dockerTagRemoteSubImages(ctx, tag, auth); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		l.Info("Tagged container image",
+			zap.String("destination_extra_reference", tag.String()),
+		)
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
 func (s *Server) dockerTagRemoteSubImages(
 	ctx context.Context,
 	ref crname.Reference,
@@ -322,9 +450,7 @@ func (s *Server) dockerTagRemoteSubImages(
 			continue
 		}
 
-		_tag := fmt.Sprintf("%s:%s-%s-%s",
-			ref.Context().Name(), ref.Identifier(), desc.Platform.OS, desc.Platform.Architecture,
-		)
+		_tag := ref.Context().Name() + ":" + dockerSubImageTag(ref.Identifier(), desc.Platform, false)
 		tag, err := crname.NewTag(_tag)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to parse a tag: %s: %w",
@@ -363,9 +489,7 @@ func (s *Server) dockerTagRemoteSubImages(
 			continue
 		}
 
-		_tag := fmt.Sprintf("%s:%s-%s-%s-attestation",
-			ref.Context().Name(), ref.Identifier(), reference.Platform.OS, reference.Platform.Architecture,
-		)
+		_tag := ref.Context().Name() + ":" + dockerSubImageTag(ref.Identifier(), reference.Platform, true)
 		tag, err := crname.NewTag(_tag)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to parse a tag: %s: %w",
@@ -392,3 +516,37 @@ func (s *Server) dockerTagRemoteSubImages(
 
 	return utils.FlattenErrors(errs)
 }
+
+// dockerSubImageTags returns the tags that dockerTagRemoteSubImages applies
+// to the images (and attestations) of the index tagged with `tag`.
+func (s *Server) dockerSubImageTags(
+	tag string,
+	indexManifest *cr.IndexManifest,
+) ([]string, error) {
+	images, attestations, err := s.dockerExtractImagesAndAttestations(indexManifest)
+
+	tags := make([]string, 0, len(images)+len(attestations))
+	for _, desc := range images {
+		tags = append(tags, dockerSubImageTag(tag, desc.Platform, false))
+	}
+	for digest := range attestations {
+		if reference, ok := images[digest]; ok {
+			tags = append(tags, dockerSubImageTag(tag, reference.Platform, true))
+		}
+	}
+
+	return tags, err
+}
+
+// dockerSubImageTag returns the tag of the platform-specific sub-image (or of
+// its attestation) of the index tagged with `tag` (e.g. `v1.0.0-linux-amd64`).
+func dockerSubImageTag(tag string, platform *cr.Platform, attestation bool) string {
+	var os, arch string
+	if platform != nil {
+		os, arch = platform.OS, platform.Architecture
+	}
+	if attestation {
+		return fmt.Sprintf("%s-%s-%s-attestation", tag, os, arch)
+	}
+	return fmt.Sprintf("%s-%s-%s", tag, os, arch)
+}
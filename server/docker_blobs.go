@@ -0,0 +1,267 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/flashbots/gh-artifacts-sync/metrics"
+
+	crname "github.com/google/go-containerregistry/pkg/name"
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	crpartial "github.com/google/go-containerregistry/pkg/v1/partial"
+	crremote "github.com/google/go-containerregistry/pkg/v1/remote"
+	crtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"go.opentelemetry.io/otel/attribute"
+	otelapi "go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+const (
+	blobExisting    = "existing"
+	blobMounted     = "mounted"
+	blobTransferred = "transferred"
+)
+
+type dockerBlobStats struct {
+	blobs map[string]int64
+	bytes map[string]int64
+}
+
+// dockerBlobRecorder observes the registry requests made while pushing the
+// image (or index) and records whether each blob already existed at the
+// destination, was mounted from another repository, or was transferred.
+type dockerBlobRecorder struct {
+	http.RoundTripper
+
+	mx       sync.Mutex
+	outcomes map[string]string
+}
+
+func newDockerBlobRecorder() *dockerBlobRecorder {
+	return &dockerBlobRecorder{
+		RoundTripper: http.DefaultTransport,
+		outcomes:     make(map[string]string),
+	}
+}
+
+func (r *dockerBlobRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	var digest, outcome string
+	switch {
+	case req.Method == http.MethodHead && res.StatusCode == http.StatusOK:
+		// existence check: HEAD /v2/<name>/blobs/<digest>
+		if _, d, ok := strings.Cut(req.URL.Path, "/blobs/"); ok && !strings.HasPrefix(d, "uploads") {
+			digest, outcome = d, blobExisting
+		}
+	case req.Method == http.MethodPost && res.StatusCode == http.StatusCreated:
+		// cross-repository mount: POST /v2/<name>/blobs/uploads/?mount=<digest>&from=<repo>
+		digest, outcome = req.URL.Query().Get("mount"), blobMounted
+	case req.Method == http.MethodPut && res.StatusCode == http.StatusCreated:
+		// upload commit: PUT <location>?digest=<digest>
+		digest, outcome = req.URL.Query().Get("digest"), blobTransferred
+	}
+	if digest == "" {
+		return res, nil
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.outcomes[digest] = outcome
+
+	return res, nil
+}
+
+// stats summarises the recorded outcomes for the blobs of the image (or of
+// all the images in the index).  The blobs that were never checked (e.g. b/c
+// the whole image was already present at the destination) count as existing.
+func (r *dockerBlobRecorder) stats(
+	image cr.Image,
+	index cr.ImageIndex,
+) (*dockerBlobStats, error) {
+	blobs, err := dockerCollectBlobs(image, index)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dockerBlobStats{
+		blobs: make(map[string]int64),
+		bytes: make(map[string]int64),
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	for digest, size := range blobs {
+		outcome, recorded := r.outcomes[digest.String()]
+		if !recorded {
+			outcome = blobExisting
+		}
+		stats.blobs[outcome]++
+		stats.bytes[outcome] += size
+	}
+
+	return stats, nil
+}
+
+// dockerCollectBlobs returns the sizes of the blobs (layers and configs) of
+// the image (or of all the images in the index).
+func dockerCollectBlobs(
+	image cr.Image,
+	index cr.ImageIndex,
+) (map[cr.Hash]int64, error) {
+	images := make([]cr.Image, 0)
+	switch {
+	case image != nil:
+		images = append(images, image)
+	case index != nil:
+		indexManifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get index manifest: %w", err)
+		}
+		for _, desc := range indexManifest.Manifests {
+			image, err := index.Image(desc.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get image from an index: %s: %w",
+					desc.Digest.String(), err,
+				)
+			}
+			images = append(images, image)
+		}
+	}
+
+	blobs := make(map[cr.Hash]int64)
+	for _, image := range images {
+		layers, err := image.Layers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image layers: %w", err)
+		}
+		if config, err := crpartial.ConfigLayer(image); err == nil {
+			layers = append(layers, config)
+		}
+		for _, layer := range layers {
+			digest, err := layer.Digest()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get layer digest: %w", err)
+			}
+			size, err := layer.Size()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get layer size: %w", err)
+			}
+			blobs[digest] = size
+		}
+	}
+
+	return blobs, nil
+}
+
+func (stats *dockerBlobStats) report(ctx context.Context, l *zap.Logger, ref crname.Reference) {
+	if stats == nil {
+		return
+	}
+
+	l.Info("Container blobs pushed to the destination",
+		zap.Int64("blobs_existing", stats.blobs[blobExisting]),
+		zap.Int64("blobs_mounted", stats.blobs[blobMounted]),
+		zap.Int64("blobs_transferred", stats.blobs[blobTransferred]),
+		zap.Int64("bytes_skipped", stats.bytes[blobExisting]+stats.bytes[blobMounted]),
+		zap.Int64("bytes_transferred", stats.bytes[blobTransferred]),
+	)
+
+	for _, outcome := range []string{blobExisting, blobMounted, blobTransferred} {
+		attrs := otelapi.WithAttributes(
+			attribute.String("outcome", outcome),
+			attribute.String("registry", ref.Context().RegistryStr()),
+		)
+		metrics.DockerBlobs.Add(ctx, stats.blobs[outcome], attrs)
+		metrics.DockerBlobBytes.Add(ctx, stats.bytes[outcome], attrs)
+	}
+}
+
+// dockerMountableImage makes remote.Write attempt to mount the layers of the
+// image from another repository (at the same registry) instead of uploading.
+type dockerMountableImage struct {
+	cr.Image
+
+	reference crname.Reference
+}
+
+func (mi *dockerMountableImage) Layers() ([]cr.Layer, error) {
+	layers, err := mi.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	mountable := make([]cr.Layer, 0, len(layers))
+	for _, layer := range layers {
+		mountable = append(mountable, &crremote.MountableLayer{
+			Layer:     layer,
+			Reference: mi.reference,
+		})
+	}
+	return mountable, nil
+}
+
+func (mi *dockerMountableImage) LayerByDigest(digest cr.Hash) (cr.Layer, error) {
+	layer, err := mi.Image.LayerByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return &crremote.MountableLayer{
+		Layer:     layer,
+		Reference: mi.reference,
+	}, nil
+}
+
+// dockerMountableIndex wraps the images of the index in dockerMountableImage.
+type dockerMountableIndex struct {
+	index     cr.ImageIndex
+	reference crname.Reference
+}
+
+func (mi *dockerMountableIndex) MediaType() (crtypes.MediaType, error) {
+	return mi.index.MediaType()
+}
+
+func (mi *dockerMountableIndex) Digest() (cr.Hash, error) {
+	return mi.index.Digest()
+}
+
+func (mi *dockerMountableIndex) Size() (int64, error) {
+	return mi.index.Size()
+}
+
+func (mi *dockerMountableIndex) IndexManifest() (*cr.IndexManifest, error) {
+	return mi.index.IndexManifest()
+}
+
+func (mi *dockerMountableIndex) RawManifest() ([]byte, error) {
+	return mi.index.RawManifest()
+}
+
+func (mi *dockerMountableIndex) Image(digest cr.Hash) (cr.Image, error) {
+	image, err := mi.index.Image(digest)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerMountableImage{
+		Image:     image,
+		reference: mi.reference,
+	}, nil
+}
+
+func (mi *dockerMountableIndex) ImageIndex(digest cr.Hash) (cr.ImageIndex, error) {
+	index, err := mi.index.ImageIndex(digest)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerMountableIndex{
+		index:     index,
+		reference: mi.reference,
+	}, nil
+}
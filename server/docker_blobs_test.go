@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDockerBlobRecorder(t *testing.T) {
+	var status int
+
+	r := newDockerBlobRecorder()
+	r.RoundTripper = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	})
+
+	for _, step := range []struct {
+		method string
+		url    string
+		status int
+	}{
+		// exists
+		{http.MethodHead, "https://registry/v2/repo/blobs/sha256:aaaa", http.StatusOK},
+
+		// mounted
+		{http.MethodHead, "https://registry/v2/repo/blobs/sha256:bbbb", http.StatusNotFound},
+		{http.MethodPost, "https://registry/v2/repo/blobs/uploads/?mount=sha256%3Abbbb&from=other", http.StatusCreated},
+
+		// mount is refused, so it's uploaded
+		{http.MethodHead, "https://registry/v2/repo/blobs/sha256:cccc", http.StatusNotFound},
+		{http.MethodPost, "https://registry/v2/repo/blobs/uploads/?mount=sha256%3Acccc&from=other", http.StatusAccepted},
+		{http.MethodPatch, "https://registry/v2/repo/blobs/uploads/1234", http.StatusAccepted},
+		{http.MethodPut, "https://registry/v2/repo/blobs/uploads/1234?digest=sha256%3Acccc", http.StatusCreated},
+
+		// upload fails
+		{http.MethodHead, "https://registry/v2/repo/blobs/sha256:dddd", http.StatusNotFound},
+		{http.MethodPost, "https://registry/v2/repo/blobs/uploads/", http.StatusAccepted},
+		{http.MethodPut, "https://registry/v2/repo/blobs/uploads/5678?digest=sha256%3Adddd", http.StatusBadRequest},
+	} {
+		status = step.status
+		req, err := http.NewRequest(step.method, step.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{
+		"sha256:aaaa": blobExisting,
+		"sha256:bbbb": blobMounted,
+		"sha256:cccc": blobTransferred,
+	}
+	for digest, outcome := range want {
+		if got := r.outcomes[digest]; got != outcome {
+			t.Errorf("outcome of %s = %q, want %q", digest, got, outcome)
+		}
+	}
+	if len(r.outcomes) != len(want) {
+		t.Errorf("outcomes = %v, want %v", r.outcomes, want)
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/job"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+	crmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+func (s *Server) dockerMutationAnnotations(
+	j job.UploadableContainer,
+	mutation *config.ContainerMutation,
+	sourceDigest string,
+	syncedAt time.Time,
+) map[string]string {
+	annotations := make(map[string]string, len(mutation.Annotations)+4)
+
+	if mutation.Provenance {
+		annotations[config.AnnotationSource] = s.cfg.Github.ServerURL() + "/" + j.GetRepoFullName()
+		annotations[config.AnnotationSourceDigest] = sourceDigest
+		annotations[config.AnnotationSourceReference] = j.GetPackageUrl()
+		annotations[config.AnnotationSyncedAt] = syncedAt.UTC().Format(time.RFC3339)
+	}
+
+	maps.Copy(annotations, mutation.Annotations)
+
+	return annotations
+}
+
+func (s *Server) dockerMutateContainer(
+	c *container,
+	annotations map[string]string,
+	mutation *config.ContainerMutation,
+) error {
+	image := c.image
+
+	if mutation.MutatesConfig() {
+		cf := c.config.DeepCopy()
+		for _, label := range mutation.StripLabels {
+			delete(cf.Config.Labels, label)
+		}
+		if len(mutation.Labels) > 0 && cf.Config.Labels == nil {
+			cf.Config.Labels = make(map[string]string, len(mutation.Labels))
+		}
+		maps.Copy(cf.Config.Labels, mutation.Labels)
+
+		_image, err := crmutate.ConfigFile(image, cf)
+		if err != nil {
+			return fmt.Errorf("failed to mutate container config: %w", err)
+		}
+		image = _image
+	}
+
+	if len(annotations) > 0 {
+		_image, ok := crmutate.Annotations(image, annotations).(cr.Image)
+		if !ok {
+			return fmt.Errorf("failed to annotate container image")
+		}
+		image = _image
+	}
+
+	digest, err := image.Digest()
+	if err != nil {
+		return fmt.Errorf("failed to get mutated container's digest: %w", err)
+	}
+
+	config, err := image.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("failed to get mutated container's config file: %w", err)
+	}
+
+	c.config = config
+	c.digest = digest
+	c.image = image
+
+	return nil
+}
+
+func (s *Server) dockerMutateIndex(
+	index cr.ImageIndex,
+	annotations map[string]string,
+) (cr.ImageIndex, error) {
+	if len(annotations) == 0 {
+		return index, nil
+	}
+
+	_index, ok := crmutate.Annotations(index, annotations).(cr.ImageIndex)
+	if !ok {
+		return nil, fmt.Errorf("failed to annotate container index")
+	}
+
+	return _index, nil
+}
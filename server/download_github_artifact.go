@@ -29,12 +29,17 @@ func (s *Server) downloadGithubArtifact(
 		}
 	}
 
+	gh, err := s.githubFor(ctx, j.GetRepoFullName())
+	if err != nil {
+		return "", err
+	}
+
 	var downloadLink string
 	{ // get the download link
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
 
-		_url, _, err := s.github.Actions.DownloadArtifact(
+		_url, _, err := gh.Actions.DownloadArtifact(
 			ctx, j.GetRepoOwner(), j.GetRepo(), j.GetArtifactID(), 16,
 		)
 		if err != nil {
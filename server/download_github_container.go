@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,7 +14,6 @@ import (
 	"github.com/flashbots/gh-artifacts-sync/job"
 	"github.com/flashbots/gh-artifacts-sync/logutils"
 
-	"github.com/golang-jwt/jwt/v5"
 	crauthn "github.com/google/go-containerregistry/pkg/authn"
 	crname "github.com/google/go-containerregistry/pkg/name"
 	cr "github.com/google/go-containerregistry/pkg/v1"
@@ -44,28 +42,19 @@ func (s *Server) downloadGithubContainer(
 
 	var auth crauthn.Authenticator
 	{ // get token
-		_jwt, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
-			"iat": time.Now().Unix(),
-			"exp": time.Now().Add(10 * time.Minute).Unix(),
-			"iss": s.cfg.Github.App.ID,
-		}).SignedString(s.cfg.Github.App.RsaPrivateKey())
+		transport, err := s.githubTransport(ctx, j.GetRepoFullName())
 		if err != nil {
-			return "", fmt.Errorf("failed to sign a jwt: %w", err)
+			return "", err
 		}
 
-		token, res, err := s.github.WithAuthToken(_jwt).Apps.CreateInstallationToken(
-			ctx, s.cfg.Github.App.InstallationID, nil,
-		)
-		if err == nil && res.StatusCode != http.StatusCreated {
-			err = fmt.Errorf("unexpected http status: %d", res.StatusCode)
-		}
+		token, err := transport.Token(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to get auth token: %w", err)
 		}
 
 		auth = crauthn.FromConfig(crauthn.AuthConfig{
 			Username: "oauth2accesstoken",
-			Password: token.GetToken(),
+			Password: token,
 		})
 	}
 
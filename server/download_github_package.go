@@ -0,0 +1,173 @@
+package server
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+)
+
+func (s *Server) downloadGithubNpmPackage(
+	ctx context.Context,
+	j *job.SyncNpmPackage,
+) (string, error) {
+	tarballUrl := j.GetTarballUrl()
+	if tarballUrl == "" {
+		return "", utils.DoNotRetry(fmt.Errorf("no tarball for npm package: %s@%s",
+			j.GetNpmName(), j.GetVersion(),
+		))
+	}
+
+	_url, err := url.Parse(tarballUrl)
+	if err != nil {
+		return "", utils.DoNotRetry(fmt.Errorf("failed to parse npm tarball url: %s: %w",
+			tarballUrl, err,
+		))
+	}
+
+	gh, err := s.githubFor(ctx, j.GetRepoOwner()+"/"+j.GetRepo())
+	if err != nil {
+		return "", err
+	}
+
+	return s.downloadGithubPackageFiles(ctx, gh.Client(),
+		filepath.Join(s.cfg.Dir.Downloads, j.GetRepoOwner(), j.GetRepo(), "npm", j.GetPackageName()),
+		strconv.Itoa(int(j.GetVersionID())),
+		map[string]string{path.Base(_url.Path): tarballUrl},
+	)
+}
+
+func (s *Server) downloadGithubMavenPackage(
+	ctx context.Context,
+	j *job.SyncMavenPackage,
+) (string, error) {
+	files := make(map[string]string, len(j.GetFiles()))
+	for _, f := range j.GetFiles() {
+		if f == nil || f.DownloadURL == nil || f.Name == nil {
+			continue
+		}
+		if strings.HasPrefix(*f.Name, "maven-metadata.xml") {
+			continue // maintained by the destination
+		}
+
+		_url, err := url.Parse(*f.DownloadURL)
+		if err != nil {
+			return "", utils.DoNotRetry(fmt.Errorf("failed to parse maven file url: %s: %w",
+				*f.DownloadURL, err,
+			))
+		}
+
+		// https://maven.pkg.github.com/OWNER/REPO/group/path/artifact/version/file
+		parts := strings.SplitN(strings.TrimPrefix(_url.Path, "/"), "/", 3)
+		if len(parts) != 3 {
+			return "", utils.DoNotRetry(fmt.Errorf("unexpected maven file url: %s",
+				*f.DownloadURL,
+			))
+		}
+
+		files[parts[2]] = *f.DownloadURL
+	}
+
+	if len(files) == 0 {
+		return "", utils.DoNotRetry(fmt.Errorf("no files for maven package: %s@%s",
+			j.GetPackageName(), j.GetVersion(),
+		))
+	}
+
+	gh, err := s.githubFor(ctx, j.GetRepoOwner()+"/"+j.GetRepo())
+	if err != nil {
+		return "", err
+	}
+
+	return s.downloadGithubPackageFiles(ctx, gh.Client(),
+		filepath.Join(s.cfg.Dir.Downloads, j.GetRepoOwner(), j.GetRepo(), "maven", j.GetPackageName()),
+		strconv.Itoa(int(j.GetVersionID())),
+		files,
+	)
+}
+
+// downloadGithubPackageFiles downloads the files (name => url) and packs them
+// into a zip archive, so that they can be uploaded with the same means as the
+// release assets and workflow artifacts.
+func (s *Server) downloadGithubPackageFiles(
+	ctx context.Context,
+	client *http.Client,
+	downloadsDir string,
+	version string,
+	files map[string]string,
+) (string, error) {
+	{ // create package downloads dir
+		if err := os.MkdirAll(downloadsDir, 0750); err != nil {
+			return "", fmt.Errorf("failed to create package download directory: %s: %w",
+				downloadsDir, err,
+			)
+		}
+	}
+
+	var fname string
+	{ // download
+		fname = filepath.Join(downloadsDir, version+".zip")
+		file, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+		if err != nil {
+			if file != nil {
+				err = errors.Join(err,
+					file.Close(),
+				)
+			}
+			return "", fmt.Errorf("failed to create download file: %w", err)
+		}
+		defer file.Close()
+
+		zipper := zip.NewWriter(file)
+
+		for name, url := range files {
+			tmp := filepath.Join(downloadsDir, version+"-"+strings.ReplaceAll(name, "/", "-"))
+			if err := s.downloadUrl(ctx, client, url, tmp, time.Minute); err != nil {
+				s.RemoveDownload(ctx, tmp)
+				return fname, fmt.Errorf("failed to download a package file: %w", err)
+			}
+
+			err := func() error {
+				defer s.RemoveDownload(ctx, tmp)
+
+				src, err := os.Open(tmp)
+				if err != nil {
+					return err
+				}
+				defer src.Close()
+
+				dst, err := zipper.Create(name)
+				if err != nil {
+					return err
+				}
+
+				_, err = io.Copy(dst, src)
+				return err
+			}()
+			if err != nil {
+				return fname, fmt.Errorf("failed to archive a package file: %s: %w", name, err)
+			}
+		}
+
+		if err := zipper.Close(); err != nil {
+			return fname, fmt.Errorf("failed to finalise package archive: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			return fname, fmt.Errorf("failed to close package archive: %w", err)
+		}
+	}
+
+	return fname, nil
+}
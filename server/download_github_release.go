@@ -27,10 +27,15 @@ func (s *Server) downloadGithubRelease(
 		}
 	}
 
+	gh, err := s.githubFor(ctx, j.GetRepoOwner()+"/"+j.GetRepo())
+	if err != nil {
+		return "", err
+	}
+
 	var fname string
 	{ // download
 		fname = filepath.Join(downloadsDir, j.GetAssetName())
-		if err := s.downloadUrl(ctx, s.github.Client(), *j.Asset.URL, fname, time.Minute); err != nil {
+		if err := s.downloadUrl(ctx, gh.Client(), *j.Asset.URL, fname, time.Minute); err != nil {
 			return "", fmt.Errorf("failed to download an asset: %w", err)
 		}
 	}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"github.com/google/go-github/v73/github"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+)
+
+// githubInstallations caches the installations of the github app per
+// repository, and the clients (transports) per installation (so that the
+// installation tokens are re-used until they expire).
+type githubInstallations struct {
+	mx         sync.Mutex
+	clients    map[int64]*github.Client
+	repos      map[string]int64 // lower-cased `owner/name` => installation id
+	transports map[int64]*ghinstallation.Transport
+}
+
+func newGithubInstallations() *githubInstallations {
+	return &githubInstallations{
+		clients:    make(map[int64]*github.Client),
+		repos:      make(map[string]int64),
+		transports: make(map[int64]*ghinstallation.Transport),
+	}
+}
+
+// rememberInstallation records the installation reported by the event for
+// the repository.
+func (s *Server) rememberInstallation(repo string, installation int64) {
+	if repo == "" || installation == 0 {
+		return
+	}
+
+	s.installations.mx.Lock()
+	defer s.installations.mx.Unlock()
+
+	s.installations.repos[strings.ToLower(repo)] = installation
+}
+
+// githubInstallation resolves the installation of the github app for the
+// repository (`owner/name`).  the one from the configuration wins, if any.
+func (s *Server) githubInstallation(ctx context.Context, repo string) (int64, error) {
+	if s.cfg.Github.App.InstallationID != 0 {
+		return s.cfg.Github.App.InstallationID, nil
+	}
+
+	key := strings.ToLower(repo)
+
+	s.installations.mx.Lock()
+	installation, known := s.installations.repos[key]
+	s.installations.mx.Unlock()
+	if known {
+		return installation, nil
+	}
+
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return 0, utils.DoNotRetry(fmt.Errorf("invalid repository name: %s", repo))
+	}
+
+	var res *github.Response
+	inst, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) (*github.Installation, error) {
+		inst, _res, err := s.githubApp.Apps.FindRepositoryInstallation(ctx, owner, name)
+		res = _res
+		return inst, err
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to find github app installation for repository: %s: %w", repo, err)
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			err = utils.DoNotRetry(err)
+		}
+		return 0, err
+	}
+
+	s.rememberInstallation(repo, inst.GetID())
+
+	return inst.GetID(), nil
+}
+
+// githubTransport returns the (cached) transport of the installation of the
+// github app for the repository.
+func (s *Server) githubTransport(ctx context.Context, repo string) (*ghinstallation.Transport, error) {
+	installation, err := s.githubInstallation(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	s.installations.mx.Lock()
+	defer s.installations.mx.Unlock()
+
+	transport, known := s.installations.transports[installation]
+	if !known {
+		transport = ghinstallation.NewFromAppsTransport(s.githubAppTransport, installation)
+		client, err := s.newGithubClient(transport)
+		if err != nil {
+			return nil, err
+		}
+		s.installations.transports[installation] = transport
+		s.installations.clients[installation] = client
+	}
+
+	return transport, nil
+}
+
+// githubFor returns the github client authenticated as the installation of
+// the github app for the repository (`owner/name`).
+func (s *Server) githubFor(ctx context.Context, repo string) (*github.Client, error) {
+	transport, err := s.githubTransport(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	s.installations.mx.Lock()
+	defer s.installations.mx.Unlock()
+
+	return s.installations.clients[transport.InstallationID()], nil
+}
+
+func (s *Server) newGithubClient(transport http.RoundTripper) (*github.Client, error) {
+	client := github.NewClient(&http.Client{
+		Transport: transport,
+	})
+
+	if s.cfg.Github.IsEnterprise() {
+		return client.WithEnterpriseURLs(
+			s.cfg.Github.BaseURL, s.cfg.Github.EnterpriseUploadURL(),
+		)
+	}
+
+	return client, nil
+}
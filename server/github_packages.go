@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"github.com/google/go-github/v73/github"
+)
+
+func (s *Server) githubGetContainerTags(
+	ctx context.Context,
+	j *job.SyncContainerRegistryPackage,
+) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var (
+		version *github.PackageVersion
+		res     *github.Response
+		err     error
+	)
+
+	gh, err := s.githubFor(ctx, j.GetRepoFullName())
+	if err != nil {
+		return nil, err
+	}
+
+	switch j.GetPackageOwnerType() {
+	case "Organization":
+		version, res, err = gh.Organizations.PackageGetVersion(
+			ctx, j.GetPackageOwner(), "container", j.GetPackageName(), j.GetVersionID(),
+		)
+	default:
+		version, res, err = gh.Users.PackageGetVersion(
+			ctx, j.GetPackageOwner(), "container", url.PathEscape(j.GetPackageName()), j.GetVersionID(),
+		)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get package version: %w", err)
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			err = utils.DoNotRetry(err)
+		}
+		return nil, err
+	}
+
+	metadata, ok := version.GetMetadata()
+	if !ok || metadata == nil || metadata.Container == nil {
+		return []string{}, nil
+	}
+
+	return metadata.Container.Tags, nil
+}
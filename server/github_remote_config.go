@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/google/go-github/v73/github"
+	"go.uber.org/zap"
+)
+
+// repositoryAt returns the configuration of the repository.  If the central
+// config enables it, the rules of the per-repository config (read from the
+// repository itself at the given ref) are merged in.
+//
+// Invalid per-repository config is ignored (with an error logged) and only
+// the central rules are returned in that case.
+func (s *Server) repositoryAt(
+	ctx context.Context,
+	name, ref string,
+) (*config.Repository, bool, error) {
+	repo, repoIsConfigured := s.repository(name)
+	if !repoIsConfigured || repo.Remote == nil {
+		return repo, repoIsConfigured, nil
+	}
+
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("repo", name),
+		zap.String("remote_config", repo.Remote.Path),
+		zap.String("remote_config_ref", ref),
+	)
+
+	bytes, err := s.githubGetFile(ctx, name, repo.Remote.Path, ref)
+	if err != nil {
+		l.Error("Failed to fetch per-repository config",
+			zap.Error(err),
+		)
+		return nil, false, err
+	}
+	if bytes == nil {
+		return repo, true, nil
+	}
+
+	remote, err := repo.Remote.Parse(bytes)
+	if err != nil {
+		l.Error("Ignoring invalid per-repository config",
+			zap.Error(err),
+		)
+		return repo, true, nil
+	}
+
+	return repo.WithRemote(remote), true, nil
+}
+
+// githubGetFile returns the contents of the file in the repository at the
+// given ref (default branch if empty), or nil if there is no such file.
+func (s *Server) githubGetFile(
+	ctx context.Context,
+	repoFullName, path, ref string,
+) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	owner, repo, found := strings.Cut(repoFullName, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid repository name: %s", repoFullName)
+	}
+
+	gh, err := s.githubFor(ctx, repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, _, res, err := gh.Repositories.GetContents(
+		ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref},
+	)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get file contents: %w", err)
+	}
+	if file == nil { // it's a directory
+		return nil, nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file contents: %w", err)
+	}
+
+	return []byte(content), nil
+}
@@ -0,0 +1,397 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"github.com/google/go-github/v73/github"
+
+	"go.uber.org/zap"
+
+	crauthn "github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	crremote "github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"google.golang.org/api/artifactregistry/v1"
+	"google.golang.org/api/googleapi"
+)
+
+func (s *Server) handleDeleteFromDestinations(
+	ctx context.Context,
+	j *job.DeleteFromDestinations,
+) error {
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("repo", j.GetRepoFullName()),
+		zap.String("release_tag", j.GetReleaseTag()),
+		zap.String("package", j.GetPackageName()),
+		zap.Int64("version_id", j.GetPackageVersionID()),
+		zap.String("version", j.GetVersion()),
+		zap.Strings("tags", j.GetTags()),
+	)
+	ctx = logutils.ContextWithLogger(ctx, l)
+
+	{ // make sure the source was not restored during the grace period
+		exists, err := s.githubSourceExists(ctx, j)
+		if err != nil {
+			l.Error("Failed to check whether the deleted source still exists", zap.Error(err))
+			return err
+		}
+		if exists {
+			l.Info("Ignoring deletion b/c the source exists again")
+			return nil
+		}
+	}
+
+	l.Info("Deleting from destinations...")
+
+	var packages *artifactregistry.ProjectsLocationsRepositoriesPackagesService
+	{ // packages service
+		_packages, err := s.gcp.ArtifactRegistryPackages(ctx)
+		if err != nil {
+			return err
+		}
+		packages = _packages
+	}
+
+	var auth crauthn.Authenticator
+
+	errs := make([]error, 0)
+
+	data := j.GetTemplateData()
+
+	for _, dst := range j.GetDestinations() {
+		dst, err := dst.Render(data)
+		if err != nil {
+			errs = append(errs, utils.DoNotRetry(err))
+			continue
+		}
+
+		l := l.With(
+			zap.String("destination_type", dst.Type),
+			zap.String("destination_path", dst.Path),
+			zap.String("destination_package", dst.Package),
+		)
+
+		switch dst.Type {
+		case config.DestinationGcpArtifactRegistryGeneric:
+			version := dst.Version
+			if version == "" {
+				version = data.Version
+			}
+			if version == "" {
+				l.Warn("Skipping destination b/c the version to delete is unknown")
+				continue
+			}
+
+			name := dst.Path + "/packages/" + url.PathEscape(dst.Package) + "/versions/" + url.PathEscape(version)
+			_, err := utils.WithTimeout(ctx, time.Minute, func(ctx context.Context) (*artifactregistry.Operation, error) {
+				return packages.Versions.Delete(name).Force(true).Context(ctx).Do()
+			})
+			err = gcpIgnoreNotFound(err)
+			if err != nil {
+				l.Error("Failed to delete version from gcp artifact registry",
+					zap.Error(err),
+					zap.String("name", name),
+				)
+				errs = append(errs, err)
+				continue
+			}
+			l.Info("Deleted version from gcp artifact registry",
+				zap.String("name", name),
+			)
+
+		case config.DestinationGcpArtifactRegistryDocker:
+			pkg, err := gcpDockerPackageName(dst.Path, dst.Package)
+			if err != nil {
+				errs = append(errs, utils.DoNotRetry(err))
+				continue
+			}
+
+			if auth == nil {
+				token, err := utils.WithTimeout(ctx, 10*time.Minute, func(ctx context.Context) (string, error) {
+					return s.gcp.AccessToken(ctx, "https://www.googleapis.com/auth/cloud-platform")
+				})
+				if err != nil {
+					l.Error("Failed to get gcp token", zap.Error(err))
+					return err
+				}
+				auth = crauthn.FromConfig(crauthn.AuthConfig{
+					Username: "oauth2accesstoken",
+					Password: token,
+				})
+			}
+
+			tags, err := s.gcpDockerTagsToDelete(ctx, packages, auth, dst, pkg, j)
+			if err != nil {
+				l.Error("Failed to resolve tags to delete from gcp artifact registry",
+					zap.Error(err),
+				)
+				errs = append(errs, err)
+				continue
+			}
+
+			for _, tag := range tags {
+				name := pkg + "/tags/" + url.PathEscape(tag)
+				_, err := utils.WithTimeout(ctx, time.Minute, func(ctx context.Context) (*artifactregistry.Empty, error) {
+					return packages.Tags.Delete(name).Context(ctx).Do()
+				})
+				err = gcpIgnoreNotFound(err)
+				if err != nil {
+					l.Error("Failed to delete tag from gcp artifact registry",
+						zap.Error(err),
+						zap.String("name", name),
+					)
+					errs = append(errs, err)
+					continue
+				}
+				l.Info("Deleted tag from gcp artifact registry",
+					zap.String("name", name),
+				)
+			}
+
+		default:
+			l.Warn("Skipping destination b/c deletions are not supported for its type")
+		}
+	}
+
+	if err := utils.FlattenErrors(errs); err != nil {
+		return err
+	}
+
+	l.Info("Done deleting from destinations")
+
+	return nil
+}
+
+// gcpDockerTagsToDelete resolves the tags of the deleted container version at
+// the docker destination.  Besides the tags of the job itself, these are the
+// other (not protected) tags of the same version and the tags of its
+// platform-specific sub-images.  The tags of the job come last, so that a
+// retry after a partial failure can still resolve the rest.
+func (s *Server) gcpDockerTagsToDelete(
+	ctx context.Context,
+	packages *artifactregistry.ProjectsLocationsRepositoriesPackagesService,
+	auth crauthn.Authenticator,
+	dst *config.Destination,
+	pkg string,
+	j *job.DeleteFromDestinations,
+) ([]string, error) {
+	l := logutils.LoggerFromContext(ctx)
+
+	own := j.GetTags()
+	extra := make([]string, 0)
+	sub := make([]string, 0)
+
+	seen := make(map[string]struct{}, len(own))
+	for _, tag := range own {
+		seen[tag] = struct{}{}
+	}
+
+	for _, tag := range own {
+		name := pkg + "/tags/" + url.PathEscape(tag)
+		_tag, err := utils.WithTimeout(ctx, time.Minute, func(ctx context.Context) (*artifactregistry.Tag, error) {
+			return packages.Tags.Get(name).Context(ctx).Do()
+		})
+		if err != nil {
+			if gcpIgnoreNotFound(err) == nil {
+				continue // already deleted
+			}
+			return nil, fmt.Errorf("failed to get tag from gcp artifact registry: %s: %w",
+				name, err,
+			)
+		}
+
+		versionTags := []string{tag}
+
+		err = packages.Tags.List(pkg).
+			Filter(fmt.Sprintf(`version="%s"`, _tag.Version)).
+			Pages(ctx, func(res *artifactregistry.ListTagsResponse) error {
+				for _, t := range res.Tags {
+					other, err := gcpResourceID(t.Name, "tags")
+					if err != nil {
+						return err
+					}
+					if _, dup := seen[other]; dup {
+						continue
+					}
+					seen[other] = struct{}{}
+					if reason := deletionKeepsTag(j, other); reason != "" {
+						l.Info("Keeping tag of the deleted container version b/c "+reason,
+							zap.String("tag", other),
+						)
+						continue
+					}
+					extra = append(extra, other)
+					versionTags = append(versionTags, other)
+				}
+				return nil
+			})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of the version in gcp artifact registry: %s: %w",
+				_tag.Version, err,
+			)
+		}
+
+		{ // tags of the sub-images
+			digest, err := gcpResourceID(_tag.Version, "versions")
+			if err != nil {
+				return nil, err
+			}
+			_ref := dst.Path + "/" + dst.Package + "@" + digest
+			ref, err := crname.ParseReference(_ref)
+			if err != nil {
+				return nil, utils.DoNotRetry(fmt.Errorf("failed to parse a reference: %s: %w",
+					_ref, err,
+				))
+			}
+			desc, err := crremote.Get(ref, crremote.WithAuth(auth), crremote.WithContext(ctx))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get a descriptor for container image: %s: %w",
+					ref.Name(), err,
+				)
+			}
+			if !desc.MediaType.IsIndex() {
+				continue
+			}
+			index, err := desc.ImageIndex()
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve container index: %s: %w",
+					ref.Name(), err,
+				)
+			}
+			indexManifest, err := index.IndexManifest()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get image index manifest from a descriptor: %s: %s: %w",
+					ref.Name(), desc.Digest.String(), err,
+				)
+			}
+			for _, tag := range versionTags {
+				tags, err := s.dockerSubImageTags(tag, indexManifest)
+				if err != nil {
+					l.Warn("There were issues while resolving tags of the sub-images",
+						zap.Error(err),
+						zap.String("tag", tag),
+					)
+				}
+				sub = append(sub, tags...)
+			}
+		}
+	}
+
+	res := make([]string, 0, len(sub)+len(extra)+len(own))
+	res = append(res, sub...)
+	res = append(res, extra...)
+	res = append(res, own...)
+
+	return res, nil
+}
+
+// deletionKeepsTag returns the reason to keep the other tag of the deleted
+// container version at the destination (or empty string if it is to be
+// deleted).  Only the tags that could have been synchronised by us are
+// deleted, the ones pushed by other means (or by other rules) are kept.
+func deletionKeepsTag(j *job.DeleteFromDestinations, tag string) string {
+	switch {
+	case !j.HasTag(tag):
+		return "it does not match the container tags configuration"
+	case j.IsProtected(tag):
+		return "it is protected"
+	default:
+		return ""
+	}
+}
+
+// gcpResourceID extracts (unescaped) id from the name of the artifact registry
+// resource (e.g. `v1.0.0` from `projects/project/locations/us/repositories/repo/packages/image/tags/v1.0.0`).
+func gcpResourceID(name, collection string) (string, error) {
+	idx := strings.LastIndex(name, "/"+collection+"/")
+	if idx == -1 {
+		return "", fmt.Errorf("unexpected gcp artifact registry resource name: %s", name)
+	}
+	return url.PathUnescape(name[idx+len(collection)+2:])
+}
+
+// githubSourceExists checks whether the deleted release (or package version)
+// is present at github again.
+func (s *Server) githubSourceExists(
+	ctx context.Context,
+	j *job.DeleteFromDestinations,
+) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	gh, err := s.githubFor(ctx, j.GetRepoFullName())
+	if err != nil {
+		return false, err
+	}
+
+	var res *github.Response
+
+	switch {
+	case j.GetReleaseTag() != "":
+		_, res, err = gh.Repositories.GetReleaseByTag(
+			ctx, j.GetRepoOwner(), j.GetRepo(), j.GetReleaseTag(),
+		)
+
+	case j.GetPackageVersionID() != 0:
+		switch j.GetPackageOwnerType() {
+		case "Organization":
+			_, res, err = gh.Organizations.PackageGetVersion(
+				ctx, j.GetPackageOwner(), "container", j.GetPackageName(), j.GetPackageVersionID(),
+			)
+		default:
+			_, res, err = gh.Users.PackageGetVersion(
+				ctx, j.GetPackageOwner(), "container", url.PathEscape(j.GetPackageName()), j.GetPackageVersionID(),
+			)
+		}
+
+	default:
+		return false, nil
+	}
+
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get the source from github: %w", err)
+	}
+
+	return true, nil
+}
+
+// gcpDockerPackageName converts docker destination (e.g.
+// `us-docker.pkg.dev/project/repo` + `image`) into the name of the artifact
+// registry package (e.g. `projects/project/locations/us/repositories/repo/packages/image`).
+func gcpDockerPackageName(path, pkg string) (string, error) {
+	parts := strings.SplitN(path, "/", 4)
+	if len(parts) < 3 || !strings.HasSuffix(parts[0], "-docker.pkg.dev") {
+		return "", fmt.Errorf("unexpected gcp docker destination path: %s", path)
+	}
+
+	location := strings.TrimSuffix(parts[0], "-docker.pkg.dev")
+	image := pkg
+	if len(parts) == 4 {
+		image = parts[3] + "/" + pkg
+	}
+
+	return fmt.Sprintf("projects/%s/locations/%s/repositories/%s/packages/%s",
+		parts[1], location, parts[2], url.PathEscape(image),
+	), nil
+}
+
+func gcpIgnoreNotFound(err error) error {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && gerr.Code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
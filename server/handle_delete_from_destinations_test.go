@@ -0,0 +1,135 @@
+package server
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/google/go-github/v73/github"
+
+	cr "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestGcpResourceID(t *testing.T) {
+	const pkg = "projects/project/locations/us/repositories/repo/packages/image"
+
+	tests := []struct {
+		name       string
+		collection string
+		want       string
+		wantErr    bool
+	}{
+		{pkg + "/tags/v1.0.0", "tags", "v1.0.0", false},
+		{pkg + "/tags/v1.0.0%2Brc1", "tags", "v1.0.0+rc1", false},
+		{pkg + "/versions/sha256:abcd", "versions", "sha256:abcd", false},
+		{pkg + "/versions/sha256%3Aabcd", "versions", "sha256:abcd", false},
+		{pkg + "/versions/sha256:abcd", "tags", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := gcpResourceID(tt.name, tt.collection)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("gcpResourceID(%q, %q) error = %v, want error %v", tt.name, tt.collection, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("gcpResourceID(%q, %q) = %q, want %q", tt.name, tt.collection, got, tt.want)
+		}
+	}
+}
+
+func TestDockerSubImageTags(t *testing.T) {
+	amd64 := cr.Hash{Algorithm: "sha256", Hex: "aaaa"}
+	arm64 := cr.Hash{Algorithm: "sha256", Hex: "bbbb"}
+
+	indexManifest := &cr.IndexManifest{
+		Manifests: []cr.Descriptor{
+			{Digest: amd64, Platform: &cr.Platform{OS: "linux", Architecture: "amd64"}},
+			{Digest: arm64, Platform: &cr.Platform{OS: "linux", Architecture: "arm64"}},
+			{
+				Digest:   cr.Hash{Algorithm: "sha256", Hex: "cccc"},
+				Platform: &cr.Platform{OS: "unknown", Architecture: "unknown"},
+				Annotations: map[string]string{
+					"vnd.docker.reference.type":   "attestation-manifest",
+					"vnd.docker.reference.digest": amd64.String(),
+				},
+			},
+		},
+	}
+
+	s := &Server{}
+	got, err := s.dockerSubImageTags("v1.0.0", indexManifest)
+	if err != nil {
+		t.Fatalf("dockerSubImageTags() error = %v", err)
+	}
+	slices.Sort(got)
+
+	want := []string{
+		"v1.0.0-linux-amd64",
+		"v1.0.0-linux-amd64-attestation",
+		"v1.0.0-linux-arm64",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("dockerSubImageTags() = %v, want %v", got, want)
+	}
+}
+
+func TestDeletionKeepsTag(t *testing.T) {
+	container := &config.Container{
+		Deletion: &config.Deletion{Protected: []string{`^latest$`}},
+		SkipTags: `-rc\d*$`,
+		Tags:     `^(v\d+\.\d+\.\d+(-rc\d*)?|latest|main)$`,
+	}
+	if err := container.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	j := job.NewDeletePackageVersion(
+		&github.Package{Name: github.Ptr("app")},
+		&github.Repository{FullName: github.Ptr("org/repo")},
+		[]string{"v1.0.0"},
+		container,
+		nil,
+		time.Now(),
+	)
+
+	tests := []struct {
+		tag     string
+		deleted bool
+	}{
+		{"v1.0.0", true},
+		{"main", true},
+		{"latest", false},     // protected
+		{"v1.0.0-rc1", false}, // skipped by `skip_tags`
+		{"manual", false},     // pushed by other means
+		{"sha-abcdef", false},
+	}
+
+	for _, tt := range tests {
+		if reason := deletionKeepsTag(j, tt.tag); (reason == "") != tt.deleted {
+			t.Errorf("deletionKeepsTag(%q) = %q, want deleted %v", tt.tag, reason, tt.deleted)
+		}
+	}
+
+	// the filters survive persisting of the job
+	dir := t.TempDir()
+	fname, err := job.Save(j, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := job.Load(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, ok := loaded.(*job.DeleteFromDestinations)
+	if !ok {
+		t.Fatalf("job.Load() = %T, want *job.DeleteFromDestinations", loaded)
+	}
+	for _, tt := range tests {
+		if reason := deletionKeepsTag(restored, tt.tag); (reason == "") != tt.deleted {
+			t.Errorf("deletionKeepsTag(%q) of restored job = %q, want deleted %v", tt.tag, reason, tt.deleted)
+		}
+	}
+}
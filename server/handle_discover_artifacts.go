@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/flashbots/gh-artifacts-sync/config"
 	"github.com/flashbots/gh-artifacts-sync/job"
 	"github.com/flashbots/gh-artifacts-sync/logutils"
 	"github.com/flashbots/gh-artifacts-sync/utils"
@@ -24,7 +25,10 @@ func (s *Server) handleDiscoverWorkflowArtifacts(
 
 	l.Info("Discovering artifacts of the workflow...")
 
-	repo, repoIsConfigured := s.cfg.Repositories[j.RepoFullName()]
+	repo, repoIsConfigured, err := s.repositoryAt(ctx, j.RepoFullName(), "")
+	if err != nil {
+		return err
+	}
 	if !repoIsConfigured {
 		l.Info("Ignoring workflow b/c we don't have configuration for this repo")
 		return nil
@@ -36,13 +40,30 @@ func (s *Server) handleDiscoverWorkflowArtifacts(
 		return nil
 	}
 
+	// re-check b/c config might have changed since the job was persisted
+	if reason := workflowRunSkipReason(workflow, j.WorkflowRunEvent.Repo, j.WorkflowRunEvent.WorkflowRun); reason != "" {
+		l.Info("Ignoring workflow b/c "+reason,
+			zap.String("actor", j.WorkflowRunEvent.WorkflowRun.GetTriggeringActor().GetLogin()),
+			zap.String("head_branch", j.WorkflowRunEvent.WorkflowRun.GetHeadBranch()),
+			zap.String("head_repo", j.WorkflowRunEvent.WorkflowRun.GetHeadRepository().GetFullName()),
+			zap.String("event", j.WorkflowRunEvent.WorkflowRun.GetEvent()),
+		)
+		return nil
+	}
+
+	gh, err := s.githubFor(ctx, j.RepoFullName())
+	if err != nil {
+		l.Error("Failed to get github client", zap.Error(err))
+		return err
+	}
+
 	artifacts := make([]*github.Artifact, 0)
 	page := 0
 	for {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
 
-		list, res, err := s.Github().Actions.ListWorkflowRunArtifacts(
+		list, res, err := gh.Actions.ListWorkflowRunArtifacts(
 			ctx, j.RepoOwner(), j.Repo(), j.WorkflowRunID(), &github.ListOptions{Page: page},
 		)
 		if err != nil {
@@ -75,27 +96,30 @@ func (s *Server) handleDiscoverWorkflowArtifacts(
 			continue
 		}
 
-		for _, cfgArtifact := range workflow.Artifacts {
-			var version string
-
-			matches := cfgArtifact.Regexp().FindStringSubmatch(
-				filepath.Base(*ghArtifact.Name),
+		run := j.WorkflowRunEvent.WorkflowRun
+		matches, err := workflow.MatchArtifact(&config.TemplateData{
+			HeadBranch: run.GetHeadBranch(),
+			HeadSHA:    *ghArtifact.WorkflowRun.HeadSHA,
+			Repo:       j.Repo(),
+			RepoOwner:  j.RepoOwner(),
+			RunNumber:  run.GetRunNumber(),
+		}, filepath.Base(*ghArtifact.Name))
+		if err != nil {
+			l.Error("Failed to extract artifact version",
+				zap.String("artifact", must(ghArtifact.Name)),
+				zap.Error(err),
 			)
-			if len(matches) == 0 {
-				continue
-			}
-
-			if len(matches) > 1 {
-				version = matches[1]
-			} else {
-				version = *ghArtifact.WorkflowRun.HeadSHA
-			}
+		}
 
+		for _, m := range matches {
 			j := job.NewSyncWorkflowArtifact(
 				ghArtifact,
-				version,
-				cfgArtifact.Destinations,
-				j.WorkflowRunEvent.WorkflowRun,
+				m.Data.Version,
+				m.Artifact.Destinations,
+				run,
+				&config.TemplateData{
+					Groups: m.Data.Groups,
+				},
 			)
 
 			if fname, err := job.Save(j, s.cfg.Dir.Jobs); err == nil {
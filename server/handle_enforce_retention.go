@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+
+	"go.uber.org/zap"
+
+	"google.golang.org/api/artifactregistry/v1"
+)
+
+func (s *Server) handleEnforceRetention(
+	ctx context.Context,
+	_ *job.EnforceRetention,
+) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	var retentions map[string][]*config.Retention
+	{
+		s.mxConfig.RLock()
+		_retentions, conflicts := retentionsByPackage(s.cfg.Repositories)
+		s.mxConfig.RUnlock()
+
+		for _, pkg := range conflicts {
+			l.Warn("Skipping retention rules b/c some destinations of the same package do not configure retention",
+				zap.String("package", pkg),
+			)
+		}
+		retentions = _retentions
+	}
+
+	if len(retentions) == 0 {
+		return nil
+	}
+
+	l.Info("Enforcing retention rules...",
+		zap.Int("packages_count", len(retentions)),
+	)
+
+	var packages *artifactregistry.ProjectsLocationsRepositoriesPackagesService
+	{ // packages service
+		_packages, err := s.gcp.ArtifactRegistryPackages(ctx)
+		if err != nil {
+			return err
+		}
+		packages = _packages
+	}
+
+	pkgs := make([]string, 0, len(retentions))
+	for pkg := range retentions {
+		pkgs = append(pkgs, pkg)
+	}
+	slices.Sort(pkgs)
+
+	errs := make([]error, 0)
+	for _, pkg := range pkgs {
+		l := l.With(
+			zap.String("package", pkg),
+		)
+
+		deleted, err := s.enforceRetention(
+			logutils.ContextWithLogger(ctx, l), packages, pkg, retentions[pkg],
+		)
+		if err != nil {
+			l.Error("Failed to enforce retention rules",
+				zap.Error(err),
+			)
+			errs = append(errs, err)
+		}
+		if deleted > 0 {
+			l.Info("Deleted versions not retained by retention rules",
+				zap.Int("count", deleted),
+			)
+		}
+	}
+
+	l.Info("Done enforcing retention rules")
+
+	if err := utils.FlattenErrors(errs); err != nil {
+		// the job is re-scheduled on the next retention interval anyway
+		return utils.DoNotRetry(err)
+	}
+
+	return nil
+}
+
+// retentionsByPackage groups the retention rules by the package they apply
+// to.  Several destinations might point to the same package, the version is
+// kept then if any of their rules keeps it.  The packages that are also
+// written to by the destinations w/o retention are left out (and reported as
+// conflicts), so that their versions are not deleted by the others' rules.
+func retentionsByPackage(
+	repositories map[string]*config.Repository,
+) (retentions map[string][]*config.Retention, conflicts []string) {
+	retentions = make(map[string][]*config.Retention)
+	unretained := make(map[string]struct{})
+
+	for _, repo := range repositories {
+		for _, dst := range repo.Destinations() {
+			if dst.Type != config.DestinationGcpArtifactRegistryGeneric {
+				continue
+			}
+			pkg := dst.Path + "/packages/" + url.PathEscape(dst.Package)
+			if dst.Retention == nil {
+				unretained[pkg] = struct{}{}
+				continue
+			}
+			retentions[pkg] = append(retentions[pkg], dst.Retention)
+		}
+	}
+
+	for pkg := range retentions {
+		if _, conflict := unretained[pkg]; conflict {
+			conflicts = append(conflicts, pkg)
+			delete(retentions, pkg)
+		}
+	}
+	slices.Sort(conflicts)
+
+	return retentions, conflicts
+}
+
+func (s *Server) enforceRetention(
+	ctx context.Context,
+	packages *artifactregistry.ProjectsLocationsRepositoriesPackagesService,
+	pkg string,
+	retentions []*config.Retention,
+) (int, error) {
+	l := logutils.LoggerFromContext(ctx)
+
+	type version struct {
+		name    string
+		id      string
+		created time.Time
+	}
+
+	versions := make([]version, 0)
+	{ // list the versions
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		err := packages.Versions.List(pkg).PageSize(1000).Pages(ctx, func(res *artifactregistry.ListVersionsResponse) error {
+			for _, v := range res.Versions {
+				created, err := time.Parse(time.RFC3339Nano, v.CreateTime)
+				if err != nil {
+					return fmt.Errorf("failed to parse create time of version %s: %w", v.Name, err)
+				}
+				id, err := url.PathUnescape(path.Base(v.Name))
+				if err != nil {
+					return fmt.Errorf("failed to parse version name %s: %w", v.Name, err)
+				}
+				versions = append(versions, version{
+					name:    v.Name,
+					id:      id,
+					created: created,
+				})
+			}
+			return nil
+		})
+		if err := gcpIgnoreNotFound(err); err != nil {
+			return 0, fmt.Errorf("failed to list versions: %w", err)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].created.After(versions[j].created)
+	})
+
+	now := time.Now()
+	deleted := 0
+	errs := make([]error, 0)
+
+iteratingVersions:
+	for idx, v := range versions {
+		for _, r := range retentions {
+			if r.Keeps(idx, v.id, v.created, now) {
+				continue iteratingVersions
+			}
+		}
+
+		_, err := utils.WithTimeout(ctx, time.Minute, func(ctx context.Context) (*artifactregistry.Operation, error) {
+			return packages.Versions.Delete(v.name).Force(true).Context(ctx).Do()
+		})
+		if err := gcpIgnoreNotFound(err); err != nil {
+			l.Error("Failed to delete version from gcp artifact registry",
+				zap.Error(err),
+				zap.String("version", v.id),
+			)
+			errs = append(errs, err)
+			continue
+		}
+
+		l.Debug("Deleted version from gcp artifact registry",
+			zap.String("version", v.id),
+			zap.Time("created", v.created),
+		)
+		deleted++
+	}
+
+	return deleted, utils.FlattenErrors(errs)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+)
+
+func TestRetentionsByPackage(t *testing.T) {
+	const path = "projects/p/locations/l/repositories/r"
+
+	generic := func(pkg string, retention *config.Retention) *config.Destination {
+		return &config.Destination{
+			Type:      config.DestinationGcpArtifactRegistryGeneric,
+			Path:      path,
+			Package:   pkg,
+			Retention: retention,
+		}
+	}
+	repo := func(destinations ...*config.Destination) *config.Repository {
+		return &config.Repository{
+			Releases: map[string]*config.Release{
+				`(v.*)`: {Assets: map[string]*config.Asset{
+					`(.*)`: {Destinations: destinations},
+				}},
+			},
+		}
+	}
+
+	keepLast := &config.Retention{KeepLast: 10}
+	keepWeek := &config.Retention{KeepNewerThan: 168 * time.Hour}
+
+	retentions, conflicts := retentionsByPackage(map[string]*config.Repository{
+		"org/a": repo(
+			generic("app", keepLast),
+			generic("lib", keepLast),
+			generic("tool", keepLast),
+		),
+		"org/b": repo(
+			generic("app", keepWeek),  // shared, both have retention
+			generic("lib", nil),       // shared, w/o retention
+			generic("unrelated", nil), // w/o retention, not shared
+		),
+	})
+
+	if want := []string{path + "/packages/lib"}; !slices.Equal(conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", conflicts, want)
+	}
+
+	want := map[string]int{
+		path + "/packages/app":  2,
+		path + "/packages/tool": 1,
+	}
+	if len(retentions) != len(want) {
+		t.Errorf("retentions = %v, want packages %v", retentions, want)
+	}
+	for pkg, count := range want {
+		if got := len(retentions[pkg]); got != count {
+			t.Errorf("retentions of %s = %d, want %d", pkg, got, count)
+		}
+	}
+}
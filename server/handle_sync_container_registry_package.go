@@ -25,6 +25,48 @@ func (s *Server) handleSyncContainerRegistryPackage(
 
 	l.Info("Synchronising container registry package...")
 
+	{ // resolve the tags
+		repo, repoIsConfigured, err := s.repositoryAt(ctx, j.GetRepoFullName(), "")
+		if err != nil {
+			return err
+		}
+		if !repoIsConfigured {
+			l.Info("Ignoring container registry package b/c we don't have configuration for this repo")
+			return nil
+		}
+
+		container, containerIsConfigured := repo.Containers[j.GetPackageName()]
+		if !containerIsConfigured {
+			l.Info("Ignoring container registry package b/c we don't have configuration for this container")
+			return nil
+		}
+
+		tags, err := s.githubGetContainerTags(ctx, j)
+		if err != nil {
+			l.Error("Failed to get the tags of container registry package", zap.Error(err))
+			return err
+		}
+
+		j.Tags = make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if container.HasTag(tag) {
+				j.Tags = append(j.Tags, tag)
+			}
+		}
+
+		if len(tags) > 0 && len(j.Tags) == 0 {
+			l.Info("Ignoring container registry package b/c none of its tags match the configuration",
+				zap.Strings("tags", tags),
+			)
+			return nil
+		}
+
+		l = l.With(
+			zap.Strings("tags", j.Tags),
+		)
+		ctx = logutils.ContextWithLogger(ctx, l)
+	}
+
 	zname, err := s.downloadGithubContainer(ctx, j)
 	if err != nil {
 		l.Error("Failed to download container registry package", zap.Error(err))
@@ -37,5 +79,7 @@ func (s *Server) handleSyncContainerRegistryPackage(
 		return err
 	}
 
+	l.Info("Done synchronising container registry package")
+
 	return nil
 }
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+
+	"go.uber.org/zap"
+)
+
+func (s *Server) handleSyncMavenPackage(
+	ctx context.Context,
+	j *job.SyncMavenPackage,
+) error {
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("repo", j.GetRepoFullName()),
+		zap.String("package", j.GetPackageName()),
+		zap.String("version", j.GetVersion()),
+		zap.Int64("version_id", j.GetVersionID()),
+	)
+	ctx = logutils.ContextWithLogger(ctx, l)
+
+	l.Info("Synchronising maven package...")
+
+	zname, err := s.downloadGithubMavenPackage(ctx, j)
+	if err != nil {
+		l.Error("Failed to download maven package", zap.Error(err))
+		s.RemoveDownload(ctx, zname)
+		return err
+	}
+
+	if err := s.uploadFromZipAndDelete(ctx, j, zname); err != nil {
+		l.Error("Failed to upload maven package", zap.Error(err))
+		return err
+	}
+
+	l.Info("Done synchronising maven package")
+
+	return nil
+}
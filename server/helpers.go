@@ -4,6 +4,9 @@ import (
 	"archive/zip"
 	"io"
 
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+
 	crtarball "github.com/google/go-containerregistry/pkg/v1/tarball"
 )
 
@@ -19,3 +22,20 @@ func must(str *string) string {
 	}
 	return *str
 }
+
+// workflowRunSkipReason returns the reason why the workflow run does not pass
+// the filters of the workflow config (or empty string if it does).
+func workflowRunSkipReason(
+	workflow *config.Workflow,
+	repo *github.Repository,
+	run *github.WorkflowRun,
+) string {
+	// the run is from a fork only when it's known to be (replayed or minimal
+	// payloads might have no head repository)
+	headRepo := run.GetHeadRepository().GetFullName()
+	fromFork := headRepo != "" && headRepo != repo.GetFullName()
+
+	return workflow.SkipReason(
+		run.GetTriggeringActor().GetLogin(), run.GetHeadBranch(), run.GetEvent(), fromFork,
+	)
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+)
+
+func TestWorkflowRunSkipReason(t *testing.T) {
+	repo := &github.Repository{FullName: github.Ptr("org/repo")}
+
+	run := func(actor, branch, event, headRepo string) *github.WorkflowRun {
+		r := &github.WorkflowRun{
+			Event:           github.Ptr(event),
+			HeadBranch:      github.Ptr(branch),
+			TriggeringActor: &github.User{Login: github.Ptr(actor)},
+		}
+		if headRepo != "" {
+			r.HeadRepository = &github.Repository{FullName: github.Ptr(headRepo)}
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		workflow *config.Workflow
+		run      *github.WorkflowRun
+		skipped  bool
+	}{
+		{
+			name:     "no filters",
+			workflow: &config.Workflow{},
+			run:      run("alice", "main", "push", "org/repo"),
+		},
+		{
+			name:     "actor allowed",
+			workflow: &config.Workflow{Actors: []string{"alice"}},
+			run:      run("alice", "main", "push", "org/repo"),
+		},
+		{
+			name:     "actor not allowed",
+			workflow: &config.Workflow{Actors: []string{"alice"}},
+			run:      run("mallory", "main", "push", "org/repo"),
+			skipped:  true,
+		},
+		{
+			name:     "branch matches",
+			workflow: &config.Workflow{Branches: []string{`^v\d+`}},
+			run:      run("alice", "v1.2.3", "push", "org/repo"),
+		},
+		{
+			name:     "branch does not match",
+			workflow: &config.Workflow{Branches: []string{`^main$`}},
+			run:      run("alice", "feature", "push", "org/repo"),
+			skipped:  true,
+		},
+		{
+			name:     "event allowed",
+			workflow: &config.Workflow{Events: []string{"push", "release"}},
+			run:      run("alice", "main", "release", "org/repo"),
+		},
+		{
+			name:     "event not allowed",
+			workflow: &config.Workflow{Events: []string{"push"}},
+			run:      run("alice", "main", "pull_request", "org/repo"),
+			skipped:  true,
+		},
+		{
+			name:     "fork",
+			workflow: &config.Workflow{},
+			run:      run("alice", "main", "pull_request", "mallory/repo"),
+			skipped:  true,
+		},
+		{
+			name:     "fork accepted",
+			workflow: &config.Workflow{AcceptForks: true},
+			run:      run("alice", "main", "pull_request", "mallory/repo"),
+		},
+		{
+			name:     "no head repository",
+			workflow: &config.Workflow{},
+			run:      run("alice", "main", "push", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.workflow.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			reason := workflowRunSkipReason(tt.workflow, repo, tt.run)
+			if skipped := reason != ""; skipped != tt.skipped {
+				t.Errorf("skipped = %v (reason: %q), want %v", skipped, reason, tt.skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+	"github.com/google/go-github/v73/github"
+	"github.com/google/renameio/v2"
+
+	"go.uber.org/zap"
+)
+
+// pollCursor tracks what was already seen for the repository while polling.
+type pollCursor struct {
+	Initialised bool             `json:"initialised"`
+	Containers  map[string]int64 `json:"containers,omitempty"`   // package name => last version id
+	Releases    int64            `json:"releases,omitempty"`     // last release asset id
+	WorkflowRun time.Time        `json:"workflow_run,omitempty"` // last update time of completed run
+}
+
+func (s *Server) pollCursorsFile() string {
+	return filepath.Join(s.cfg.Dir.State, "poll-cursors.json")
+}
+
+func (s *Server) loadPollCursors() (map[string]*pollCursor, error) {
+	cursors := make(map[string]*pollCursor)
+
+	bytes, err := os.ReadFile(s.pollCursorsFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read poll cursors: %w", err)
+	}
+
+	if err := json.Unmarshal(bytes, &cursors); err != nil {
+		return nil, fmt.Errorf("failed to parse poll cursors: %w", err)
+	}
+
+	return cursors, nil
+}
+
+func (s *Server) savePollCursors(cursors map[string]*pollCursor) error {
+	bytes, err := json.Marshal(cursors)
+	if err != nil {
+		return err
+	}
+	if err := renameio.WriteFile(s.pollCursorsFile(), bytes, 0640); err != nil {
+		return fmt.Errorf("failed to persist poll cursors: %w", err)
+	}
+	return nil
+}
+
+// poll lists new releases, completed workflow runs, and container versions of
+// the configured repositories, and processes them the same way as the
+// corresponding webhook events.
+func (s *Server) poll(ctx context.Context) {
+	l := logutils.LoggerFromContext(ctx)
+
+	cursors, err := s.loadPollCursors()
+	if err != nil {
+		l.Error("Failed to poll github", zap.Error(err))
+		return
+	}
+
+	var names []string
+	{
+		s.mxConfig.RLock()
+		for name := range s.cfg.Repositories {
+			names = append(names, name)
+		}
+		s.mxConfig.RUnlock()
+		slices.Sort(names)
+	}
+
+	for _, name := range names {
+		l := l.With(
+			zap.String("repo", name),
+		)
+
+		cursor, known := cursors[name]
+		if !known {
+			cursor = &pollCursor{}
+			cursors[name] = cursor
+		}
+
+		// the repo is not added to the context logger b/c event processing
+		// adds it on its own
+		if err := s.pollRepository(ctx, name, cursor); err != nil {
+			l.Error("Failed to poll github repository", zap.Error(err))
+		}
+
+		if err := s.savePollCursors(cursors); err != nil {
+			l.Error("Failed to poll github", zap.Error(err))
+			return
+		}
+	}
+}
+
+// pollRepository advances the cursor past everything that was processed.
+// on the very first poll the cursor is just initialised (so that the whole
+// history is not synchronised all at once).
+func (s *Server) pollRepository(ctx context.Context, name string, cursor *pollCursor) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	owner, repoName, ok := strings.Cut(name, "/")
+	if !ok {
+		return utils.DoNotRetry(fmt.Errorf("invalid repository name: %s", name))
+	}
+
+	gh, err := s.githubFor(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	ghRepo, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) (*github.Repository, error) {
+		repo, _, err := gh.Repositories.Get(ctx, owner, repoName)
+		return repo, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	errs := make([]error, 0)
+
+	if err := s.pollReleases(ctx, gh, owner, repoName, ghRepo, cursor); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := s.pollWorkflowRuns(ctx, gh, owner, repoName, ghRepo, cursor); err != nil {
+		errs = append(errs, err)
+	}
+
+	if repo, repoIsConfigured, err := s.repositoryAt(ctx, name, ""); err != nil {
+		errs = append(errs, err)
+	} else if repoIsConfigured {
+		if err := s.pollContainers(ctx, gh, repo, ghRepo, cursor); err != nil {
+			errs = append(errs, err)
+		}
+		s.pollWarnPackages(ctx, name, repo)
+	}
+
+	if !cursor.Initialised && len(errs) == 0 {
+		l.Info("Initialised github polling cursor",
+			zap.String("repo", name),
+		)
+		cursor.Initialised = true
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+func (s *Server) pollReleases(
+	ctx context.Context,
+	gh *github.Client,
+	owner, repoName string,
+	ghRepo *github.Repository,
+	cursor *pollCursor,
+) error {
+	// releases are listed from the newest to the oldest, so the pages are
+	// fetched until the one that has the assets seen already
+	releases := make([]*github.RepositoryRelease, 0)
+	for page := 1; page != 0; {
+		var next int
+		list, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) ([]*github.RepositoryRelease, error) {
+			list, res, err := gh.Repositories.ListReleases(ctx, owner, repoName, &github.ListOptions{Page: page, PerPage: 100})
+			if res != nil {
+				next = res.NextPage
+			}
+			return list, err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list releases: %w", err)
+		}
+		releases = append(releases, list...)
+
+		page = next
+		if !cursor.Initialised || slices.ContainsFunc(list, func(release *github.RepositoryRelease) bool {
+			return slices.ContainsFunc(release.Assets, func(asset *github.ReleaseAsset) bool {
+				return asset.GetID() <= cursor.Releases
+			})
+		}) {
+			break
+		}
+	}
+
+	last, pending := cursor.Releases, int64(0)
+	for _, release := range releases {
+		assets := make([]*github.ReleaseAsset, 0, len(release.Assets))
+		for _, asset := range release.Assets {
+			if asset.GetID() <= cursor.Releases {
+				continue
+			}
+			if asset.GetState() != "uploaded" {
+				// don't move the cursor past the assets that are still
+				// being uploaded
+				if pending == 0 || asset.GetID() < pending {
+					pending = asset.GetID()
+				}
+				continue
+			}
+			assets = append(assets, asset)
+			last = max(last, asset.GetID())
+		}
+		if len(assets) == 0 || !cursor.Initialised {
+			continue
+		}
+
+		if release.Name == nil || *release.Name == "" {
+			release.Name = release.TagName
+		}
+		release.Assets = assets
+
+		// new assets of the existing release is what github reports as
+		// `edited` action (it's also free of the late delivery warnings)
+		if err := s.processEvent(ctx, &github.ReleaseEvent{
+			Action:  github.Ptr("edited"),
+			Release: release,
+			Repo:    ghRepo,
+		}); err != nil {
+			return err
+		}
+	}
+	if pending != 0 {
+		last = min(last, pending-1)
+	}
+	cursor.Releases = last
+
+	return nil
+}
+
+func (s *Server) pollWorkflowRuns(
+	ctx context.Context,
+	gh *github.Client,
+	owner, repoName string,
+	ghRepo *github.Repository,
+	cursor *pollCursor,
+) error {
+	// runs are listed from the newest to the oldest, so the pages are fetched
+	// until the one that has the runs seen already
+	workflowRuns := make([]*github.WorkflowRun, 0)
+	for page := 1; page != 0; {
+		var next int
+		runs, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) (*github.WorkflowRuns, error) {
+			runs, res, err := gh.Actions.ListRepositoryWorkflowRuns(ctx, owner, repoName, &github.ListWorkflowRunsOptions{
+				Status:      "completed",
+				ListOptions: github.ListOptions{Page: page, PerPage: 100},
+			})
+			if res != nil {
+				next = res.NextPage
+			}
+			return runs, err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list workflow runs: %w", err)
+		}
+		workflowRuns = append(workflowRuns, runs.WorkflowRuns...)
+
+		page = next
+		if !cursor.Initialised || slices.ContainsFunc(runs.WorkflowRuns, func(run *github.WorkflowRun) bool {
+			return !run.GetUpdatedAt().After(cursor.WorkflowRun)
+		}) {
+			break
+		}
+	}
+
+	// process from the oldest to the newest, so that the cursor could be
+	// advanced step by step
+	slices.SortStableFunc(workflowRuns, func(a, b *github.WorkflowRun) int {
+		return a.GetUpdatedAt().Compare(b.GetUpdatedAt().Time)
+	})
+
+	for _, run := range workflowRuns {
+		updatedAt := run.GetUpdatedAt().Time
+		if !updatedAt.After(cursor.WorkflowRun) {
+			continue
+		}
+		if cursor.Initialised {
+			if err := s.processEvent(ctx, &github.WorkflowRunEvent{
+				Action: github.Ptr("completed"),
+				Repo:   ghRepo,
+				Workflow: &github.Workflow{
+					ID:   run.WorkflowID,
+					Name: run.Name,
+					Path: run.Path,
+				},
+				WorkflowRun: run,
+			}); err != nil {
+				return err
+			}
+		}
+		cursor.WorkflowRun = updatedAt
+	}
+
+	return nil
+}
+
+func (s *Server) pollContainers(
+	ctx context.Context,
+	gh *github.Client,
+	repo *config.Repository,
+	ghRepo *github.Repository,
+	cursor *pollCursor,
+) error {
+	if len(repo.Containers) == 0 {
+		return nil
+	}
+
+	if cursor.Containers == nil {
+		cursor.Containers = make(map[string]int64)
+	}
+
+	owner := ghRepo.GetOwner().GetLogin()
+
+	errs := make([]error, 0)
+	for _, pkg := range slices.Sorted(maps.Keys(repo.Containers)) {
+		versions, err := s.pollContainerVersions(ctx, gh, owner, ghRepo.GetOwner().GetType(), pkg, cursor)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list container versions: %s: %w", pkg, err))
+			continue
+		}
+
+		slices.SortFunc(versions, func(a, b *github.PackageVersion) int {
+			return cmp.Compare(a.GetID(), b.GetID())
+		})
+
+		for _, version := range versions {
+			if version.GetID() <= cursor.Containers[pkg] {
+				continue
+			}
+			if cursor.Initialised {
+				if err := s.processEvent(ctx, s.pollContainerEvent(ghRepo, pkg, version)); err != nil {
+					errs = append(errs, err)
+					break
+				}
+			}
+			cursor.Containers[pkg] = version.GetID()
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
+
+// pollWarnPackages reports the maven and npm packages of the repository, that
+// are not polled (github api does not list the files of their versions).
+func (s *Server) pollWarnPackages(ctx context.Context, name string, repo *config.Repository) {
+	l := logutils.LoggerFromContext(ctx)
+
+	if len(repo.Maven)+len(repo.Npm) == 0 {
+		return
+	}
+
+	packages := slices.Concat(
+		slices.Sorted(maps.Keys(repo.Maven)),
+		slices.Sorted(maps.Keys(repo.Npm)),
+	)
+
+	if s.cfg.Github.DisableWebhook {
+		l.Warn("Maven and npm packages are not polled and the webhook is disabled, they will not be synchronised",
+			zap.String("repo", name),
+			zap.Strings("packages", packages),
+		)
+		return
+	}
+
+	l.Debug("Maven and npm packages are not polled, they are synchronised via the webhook only",
+		zap.String("repo", name),
+		zap.Strings("packages", packages),
+	)
+}
+
+// pollContainerVersions lists the versions of the container from the newest
+// to the oldest, until the page that has the versions seen already.
+func (s *Server) pollContainerVersions(
+	ctx context.Context,
+	gh *github.Client,
+	owner, ownerType, pkg string,
+	cursor *pollCursor,
+) ([]*github.PackageVersion, error) {
+	versions := make([]*github.PackageVersion, 0)
+	for page := 1; page != 0; {
+		var next int
+		list, err := utils.WithTimeout(ctx, 30*time.Second, func(ctx context.Context) ([]*github.PackageVersion, error) {
+			opts := &github.PackageListOptions{ListOptions: github.ListOptions{Page: page, PerPage: 100}}
+			var (
+				list []*github.PackageVersion
+				res  *github.Response
+				err  error
+			)
+			switch ownerType {
+			case "Organization":
+				list, res, err = gh.Organizations.PackageGetAllVersions(ctx, owner, "container", pkg, opts)
+			default:
+				list, res, err = gh.Users.PackageGetAllVersions(ctx, owner, "container", url.PathEscape(pkg), opts)
+			}
+			if res != nil {
+				next = res.NextPage
+			}
+			return list, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, list...)
+
+		page = next
+		if !cursor.Initialised || slices.ContainsFunc(list, func(version *github.PackageVersion) bool {
+			return version.GetID() <= cursor.Containers[pkg]
+		}) {
+			break
+		}
+	}
+	return versions, nil
+}
+
+// pollContainerEvent makes up the registry package event (like the one github
+// sends on container publication) for the listed container version.
+func (s *Server) pollContainerEvent(
+	ghRepo *github.Repository,
+	pkg string,
+	version *github.PackageVersion,
+) *github.RegistryPackageEvent {
+	var tag string
+	if metadata, ok := version.GetMetadata(); ok && metadata != nil && metadata.Container != nil && len(metadata.Container.Tags) > 0 {
+		tag = metadata.Container.Tags[0]
+	}
+
+	digest := version.GetName() // container versions are named by digest
+	packageURL := s.cfg.Github.RegistryHost + "/" + strings.ToLower(ghRepo.GetOwner().GetLogin()) + "/" + strings.ToLower(pkg) + ":" + tag
+
+	return &github.RegistryPackageEvent{
+		Action: github.Ptr("published"),
+		RegistryPackage: &github.Package{
+			Ecosystem:   github.Ptr("CONTAINER"),
+			Name:        github.Ptr(pkg),
+			Owner:       ghRepo.GetOwner(),
+			PackageType: github.Ptr("CONTAINER"),
+			PackageVersion: &github.PackageVersion{
+				ContainerMetadata: &github.PackageEventContainerMetadata{
+					Tag: &github.PackageEventContainerMetadataTag{
+						Digest: github.Ptr(digest),
+						Name:   github.Ptr(tag),
+					},
+				},
+				ID:         version.ID,
+				PackageURL: github.Ptr(packageURL),
+				Version:    github.Ptr(digest),
+			},
+		},
+		Repository: ghRepo,
+	}
+}
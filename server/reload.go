@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"go.uber.org/zap"
+)
+
+// repository returns the configuration of the repository (safe to be called
+// concurrently with the config reloads).
+func (s *Server) repository(name string) (*config.Repository, bool) {
+	s.mxConfig.RLock()
+	defer s.mxConfig.RUnlock()
+
+	repo, isConfigured := s.cfg.Repositories[name]
+	return repo, isConfigured
+}
+
+// reloadConfig re-reads the configuration file and, if it is valid, swaps the
+// repositories and the log level.  Already persisted jobs are not affected
+// since they carry their own copy of the destinations.
+func (s *Server) reloadConfig(ctx context.Context, reason string) {
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("config", s.cfg.File()),
+		zap.String("reason", reason),
+	)
+
+	s.mxConfig.RLock()
+	cfg, err := s.cfg.Reload()
+	s.mxConfig.RUnlock()
+
+	if err != nil {
+		l.Error("Failed to reload the configuration, keeping the current one",
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := logutils.SetLevel(cfg.Log.Level); err != nil {
+		l.Warn("Failed to update the log level",
+			zap.Error(err),
+		)
+	}
+
+	s.mxConfig.Lock()
+	s.cfg.Repositories = cfg.Repositories
+	s.cfg.Log.Level = cfg.Log.Level
+	s.mxConfig.Unlock()
+
+	l.Info("Reloaded the configuration",
+		zap.Int("repositories_count", len(cfg.Repositories)),
+		zap.String("log_level", cfg.Log.Level),
+	)
+}
+
+// configChanged reports whether the contents of the configuration file(s)
+// have changed since the last call.
+func (s *Server) configChanged(ctx context.Context) bool {
+	l := logutils.LoggerFromContext(ctx)
+
+	checksum, err := s.cfg.Checksum()
+	if err != nil {
+		l.Warn("Failed to read the configuration",
+			zap.String("config", s.cfg.File()),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	if checksum == s.configChecksum {
+		return false
+	}
+	s.configChecksum = checksum
+
+	return true
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/google/go-github/v73/github"
+	"github.com/google/renameio/v2"
+
+	"go.uber.org/zap"
+)
+
+// Delivery is the raw webhook delivery as archived on disk.
+type Delivery struct {
+	ID         string            `json:"id"`
+	Event      string            `json:"event"`
+	Headers    map[string]string `json:"headers"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Payload    json.RawMessage   `json:"payload"`
+}
+
+var (
+	errDeliveryMissingEvent   = errors.New("delivery is missing event type")
+	errDeliveryMissingPayload = errors.New("delivery is missing payload")
+)
+
+// archivedHeaders are the headers persisted along with the payload (the rest
+// of them, e.g. the ones added by the proxies, are not relevant).
+var archivedHeaders = []string{
+	"Content-Type",
+	"User-Agent",
+	"X-GitHub-Delivery",
+	"X-GitHub-Event",
+	"X-GitHub-Hook-ID",
+	"X-GitHub-Hook-Installation-Target-ID",
+	"X-GitHub-Hook-Installation-Target-Type",
+	"X-Hub-Signature-256",
+}
+
+// newDelivery captures the raw (already signature-validated) webhook
+// delivery.
+func newDelivery(r *http.Request, payload []byte) *Delivery {
+	d := &Delivery{
+		ID:         github.DeliveryID(r),
+		Event:      github.WebHookType(r),
+		Headers:    make(map[string]string, len(archivedHeaders)),
+		ReceivedAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+	for _, h := range archivedHeaders {
+		if v := r.Header.Get(h); v != "" {
+			d.Headers[h] = v
+		}
+	}
+	return d
+}
+
+// archiveDelivery persists the delivery into the deliveries directory.
+func (s *Server) archiveDelivery(d *Delivery) (string, error) {
+	id := d.ID
+	if id == "" {
+		id = fmt.Sprintf("noid-%d", d.ReceivedAt.UnixNano())
+	}
+
+	bytes, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+
+	fname := filepath.Join(s.cfg.Dir.Deliveries, filepath.Base(id)+".json")
+	if err := renameio.WriteFile(fname, bytes, 0640); err != nil {
+		return "", err
+	}
+
+	return fname, nil
+}
+
+// LoadDelivery reads the delivery from the file.  The file is either the one
+// archived by the server, or just the json payload of the webhook (in which
+// case the event type must be provided).
+func LoadDelivery(path, event string) (*Delivery, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Delivery{}
+	if err := json.Unmarshal(bytes, d); err != nil {
+		return nil, fmt.Errorf("failed to parse delivery: %s: %w", path, err)
+	}
+
+	if len(d.Payload) == 0 && d.Event == "" {
+		// not an archived delivery, must be a raw payload
+		d = &Delivery{
+			ID:      strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Payload: bytes,
+		}
+	}
+	if event != "" {
+		d.Event = event
+	}
+
+	if d.Event == "" {
+		return nil, fmt.Errorf("%w: %s", errDeliveryMissingEvent, path)
+	}
+	if len(d.Payload) == 0 {
+		return nil, fmt.Errorf("%w: %s", errDeliveryMissingPayload, path)
+	}
+
+	return d, nil
+}
+
+// Replay feeds the delivery through the same processing as the webhook
+// (except for the signature validation and the deduplication).
+func (s *Server) Replay(ctx context.Context, d *Delivery) error {
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("delivery", d.ID),
+		zap.String("event", d.Event),
+	)
+
+	event, err := github.ParseWebHook(d.Event, d.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook event: %w", err)
+	}
+
+	l.Info("Replaying webhook delivery...")
+
+	return s.processEvent(logutils.ContextWithLogger(ctx, l), event)
+}
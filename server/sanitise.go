@@ -2,6 +2,7 @@ package server
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/google/go-github/v73/github"
 )
@@ -35,20 +36,22 @@ func (s *Server) sanitiseRegistryPackageEvent(e *github.RegistryPackageEvent) er
 		return errors.New("missing registry package version info")
 	}
 
-	if e.RegistryPackage.PackageVersion.ContainerMetadata == nil {
-		return errors.New("missing registry package container metadata info")
-	}
+	if strings.ToUpper(*e.RegistryPackage.Ecosystem) == ecosystemContainer {
+		if e.RegistryPackage.PackageVersion.ContainerMetadata == nil {
+			return errors.New("missing registry package container metadata info")
+		}
 
-	if e.RegistryPackage.PackageVersion.ContainerMetadata.Tag == nil {
-		return errors.New("missing registry package container tag info")
-	}
+		if e.RegistryPackage.PackageVersion.ContainerMetadata.Tag == nil {
+			return errors.New("missing registry package container tag info")
+		}
 
-	if e.RegistryPackage.PackageVersion.ContainerMetadata.Tag.Digest == nil {
-		return errors.New("missing registry package container digest")
-	}
+		if e.RegistryPackage.PackageVersion.ContainerMetadata.Tag.Digest == nil {
+			return errors.New("missing registry package container digest")
+		}
 
-	if e.RegistryPackage.PackageVersion.ContainerMetadata.Tag.Name == nil {
-		return errors.New("missing registry package container tag name")
+		if e.RegistryPackage.PackageVersion.ContainerMetadata.Tag.Name == nil {
+			return errors.New("missing registry package container tag name")
+		}
 	}
 
 	if e.RegistryPackage.PackageVersion.ID == nil {
@@ -43,6 +43,10 @@ func (s *Server) schedulerIngestJobs(_ time.Time) {
 		if err != nil {
 			j = job.NewCleanupUnparseableJob(path, err)
 		}
+		if deferred, ok := j.(job.Deferred); ok && time.Now().Before(deferred.GetNotBefore()) {
+			// not due yet, will pick it up on one of the next ticks
+			return nil
+		}
 		s.jobs <- j
 		s.jobInFlight.Add(1)
 		return nil
@@ -69,12 +73,24 @@ func (s *Server) schedulerHandleJob(ctx context.Context, j job.Job) {
 		case *job.CleanupUnparseableJob:
 			err = s.handleCleanupUnparseableJob(ctx, j)
 
+		case *job.DeleteFromDestinations:
+			err = s.handleDeleteFromDestinations(ctx, j)
+
 		case *job.DiscoverWorkflowArtifacts:
 			err = s.handleDiscoverWorkflowArtifacts(ctx, j)
 
+		case *job.EnforceRetention:
+			err = s.handleEnforceRetention(ctx, j)
+
 		case *job.SyncContainerRegistryPackage:
 			err = s.handleSyncContainerRegistryPackage(ctx, j)
 
+		case *job.SyncMavenPackage:
+			err = s.handleSyncMavenPackage(ctx, j)
+
+		case *job.SyncNpmPackage:
+			err = s.handleSyncNpmPackage(ctx, j)
+
 		case *job.SyncReleaseAsset:
 			err = s.handleSyncRepositoryRelease(ctx, j)
 
@@ -99,3 +115,19 @@ func (s *Server) schedulerHandleJob(ctx context.Context, j job.Job) {
 
 	s.RemoveJob(ctx, j)
 }
+
+func (s *Server) scheduleRetention(ctx context.Context) {
+	l := logutils.LoggerFromContext(ctx)
+
+	fname, err := job.Save(job.NewEnforceRetention(), s.cfg.Dir.Jobs)
+	if err != nil {
+		l.Error("Failed to persist a job",
+			zap.Error(err),
+		)
+		return
+	}
+
+	l.Debug("Persisted a job",
+		zap.String("job", fname),
+	)
+}
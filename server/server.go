@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -25,15 +28,21 @@ import (
 )
 
 type Server struct {
-	cfg *config.Config
+	cfg            *config.Config
+	configChecksum [32]byte
+	mxConfig       sync.RWMutex
 
-	failure chan error
+	deliveries *deliveries
+	failure    chan error
 
-	gcp    *gcp.Client
-	github *github.Client
-	logger *zap.Logger
-	server *http.Server
-	ticker *time.Ticker
+	gcp       *gcp.Client
+	githubApp *github.Client // authenticated as the app (not installation)
+	logger    *zap.Logger
+	server    *http.Server
+	ticker    *time.Ticker
+
+	githubAppTransport *ghinstallation.AppsTransport
+	installations      *githubInstallations
 
 	jobs        chan job.Job
 	jobInFlight *atomic.Int64
@@ -50,8 +59,20 @@ func New(cfg *config.Config) (*Server, error) {
 		ticker:      time.NewTicker(5 * time.Second),
 	}
 
+	if cfg.Server.DedupeDeliveries > 0 {
+		d, err := newDeliveries(
+			filepath.Join(cfg.Dir.State, "deliveries"), cfg.Server.DedupeDeliveries,
+		)
+		if err != nil {
+			return nil, err
+		}
+		s.deliveries = d
+	}
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", s.webhook)
+	if !cfg.Github.DisableWebhook {
+		mux.HandleFunc("/", s.webhook)
+	}
 	mux.Handle("/metrics", promhttp.Handler())
 	handler := httplogger.Middleware(s.logger, mux)
 
@@ -65,18 +86,25 @@ func New(cfg *config.Config) (*Server, error) {
 		WriteTimeout:      30 * time.Second,
 	}
 
-	transport, err := ghinstallation.New(
+	appTransport, err := ghinstallation.NewAppsTransport(
 		http.DefaultTransport,
 		cfg.Github.App.ID,
-		cfg.Github.App.InstallationID,
 		[]byte(cfg.Github.App.PrivateKey),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialise github app: %w", err)
 	}
-	s.github = github.NewClient(&http.Client{
-		Transport: transport,
-	})
+	githubApp, err := s.newGithubClient(appTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise github client: %w", err)
+	}
+	if cfg.Github.IsEnterprise() {
+		// installation tokens must be requested from the same api
+		appTransport.BaseURL = strings.TrimSuffix(githubApp.BaseURL.String(), "/")
+	}
+	s.githubApp = githubApp
+	s.githubAppTransport = appTransport
+	s.installations = newGithubInstallations()
 
 	return s, nil
 }
@@ -97,6 +125,38 @@ func (s *Server) Run() error {
 		}
 	}()
 
+	if s.cfg.Server.RetentionInterval > 0 {
+		go func() { // schedule the retention enforcement
+			t := time.NewTicker(s.cfg.Server.RetentionInterval)
+			defer t.Stop()
+			for range t.C {
+				s.scheduleRetention(ctx)
+			}
+		}()
+	}
+
+	if s.cfg.Github.CatchUpInterval > 0 {
+		go func() { // catch up with missed webhook deliveries
+			t := time.NewTicker(s.cfg.Github.CatchUpInterval)
+			defer t.Stop()
+			for {
+				s.catchUp(ctx)
+				<-t.C
+			}
+		}()
+	}
+
+	if s.cfg.Github.PollInterval > 0 {
+		go func() { // poll github
+			t := time.NewTicker(s.cfg.Github.PollInterval)
+			defer t.Stop()
+			for {
+				s.poll(ctx)
+				<-t.C
+			}
+		}()
+	}
+
 	go func() { // run the server
 		l.Info("Github artifacts sync server is going up...",
 			zap.String("server_listen_address", s.cfg.Server.ListenAddress),
@@ -108,32 +168,57 @@ func (s *Server) Run() error {
 	}()
 
 	errs := []error{}
-	{ // wait until termination or internal failure
+	{ // wait until termination or internal failure (reloading config meanwhile)
 		terminator := make(chan os.Signal, 1)
 		signal.Notify(terminator, os.Interrupt, syscall.SIGTERM)
 
-		select {
-		case stop := <-terminator:
-			l.Info("Stop signal received; shutting down...",
-				zap.String("signal", stop.String()),
-			)
-		case err := <-s.failure:
-			l.Error("Internal failure; shutting down...",
-				zap.Error(err),
-			)
-			errs = append(errs, err)
-		exhaustErrors:
-			for { // exhaust the errors
-				select {
-				case err := <-s.failure:
-					l.Error("Extra internal failure",
-						zap.Error(err),
-					)
-					errs = append(errs, err)
-				default:
-					break exhaustErrors
+		reloader := make(chan os.Signal, 1)
+		signal.Notify(reloader, syscall.SIGHUP)
+
+		var watcher <-chan time.Time
+		if s.cfg.File() != "" && s.cfg.Server.ConfigWatchInterval > 0 {
+			_ = s.configChanged(ctx) // initialise the checksum
+			t := time.NewTicker(s.cfg.Server.ConfigWatchInterval)
+			defer t.Stop()
+			watcher = t.C
+		}
+
+		for {
+			select {
+			case sig := <-reloader:
+				if watcher != nil {
+					_ = s.configChanged(ctx) // don't reload same changes twice
+				}
+				s.reloadConfig(ctx, sig.String())
+				continue
+			case <-watcher:
+				if s.configChanged(ctx) {
+					s.reloadConfig(ctx, "file changed")
+				}
+				continue
+			case stop := <-terminator:
+				l.Info("Stop signal received; shutting down...",
+					zap.String("signal", stop.String()),
+				)
+			case err := <-s.failure:
+				l.Error("Internal failure; shutting down...",
+					zap.Error(err),
+				)
+				errs = append(errs, err)
+			exhaustErrors:
+				for { // exhaust the errors
+					select {
+					case err := <-s.failure:
+						l.Error("Extra internal failure",
+							zap.Error(err),
+						)
+						errs = append(errs, err)
+					default:
+						break exhaustErrors
+					}
 				}
 			}
+			break
 		}
 	}
 
@@ -159,7 +244,3 @@ func (s *Server) Run() error {
 func (s *Server) Config() *config.Config {
 	return s.cfg
 }
-
-func (s *Server) Github() *github.Client {
-	return s.github
-}
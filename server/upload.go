@@ -12,6 +12,7 @@ import (
 	"github.com/flashbots/gh-artifacts-sync/logutils"
 	"github.com/flashbots/gh-artifacts-sync/utils"
 
+	crname "github.com/google/go-containerregistry/pkg/name"
 	"go.uber.org/zap"
 )
 
@@ -42,7 +43,19 @@ func (s *Server) uploadFromZip(
 
 	l := logutils.LoggerFromContext(ctx)
 
+	// docker destinations at the same registry can mount the blobs of the
+	// previously pushed ones instead of uploading them again
+	mounts := make(map[string]crname.Reference)
+
+	data := j.GetTemplateData()
+
 	for _, dst := range j.GetDestinations() {
+		dst, err := dst.Render(data)
+		if err != nil {
+			errs = append(errs, utils.DoNotRetry(err))
+			continue
+		}
+
 		_ctx := logutils.ContextWithLogger(ctx, l.With(
 			zap.String("destination_type", dst.Type),
 			zap.String("destination_path", dst.Path),
@@ -51,17 +64,35 @@ func (s *Server) uploadFromZip(
 
 		switch dst.Type {
 		case config.DestinationGcpArtifactRegistryGeneric:
-			if jf := j.(job.UploadableFile); jf != nil {
+			if jf, ok := j.(job.UploadableFile); ok {
 				errs = append(errs,
 					s.uploadFromZipToGcpArtifactRegistryGeneric(_ctx, jf, zname, dst),
 				)
+			} else {
+				errs = append(errs, errUploadUnsupportedDestination(dst))
 			}
 
 		case config.DestinationGcpArtifactRegistryDocker:
-			if jc := j.(job.UploadableContainer); jc != nil {
+			if jc, ok := j.(job.UploadableContainer); ok {
 				errs = append(errs,
-					s.uploadFromZipToGcpArtifactRegistryDocker(_ctx, jc, zname, dst),
+					s.uploadFromZipToGcpArtifactRegistryDocker(_ctx, jc, zname, dst, mounts),
 				)
+			} else {
+				errs = append(errs, errUploadUnsupportedDestination(dst))
+			}
+
+		case config.DestinationGcpArtifactRegistryMaven:
+			errs = append(errs,
+				s.uploadFromZipToGcpArtifactRegistryMaven(_ctx, j, zname, dst),
+			)
+
+		case config.DestinationGcpArtifactRegistryNpm:
+			if jn, ok := j.(job.UploadableNpmPackage); ok {
+				errs = append(errs,
+					s.uploadFromZipToGcpArtifactRegistryNpm(_ctx, jn, zname, dst),
+				)
+			} else {
+				errs = append(errs, errUploadUnsupportedDestination(dst))
 			}
 
 		default:
@@ -73,3 +104,9 @@ func (s *Server) uploadFromZip(
 
 	return utils.FlattenErrors(errs)
 }
+
+func errUploadUnsupportedDestination(dst *config.Destination) error {
+	return utils.DoNotRetry(fmt.Errorf("destination type is not supported for this kind of job: %s",
+		dst.Type,
+	))
+}
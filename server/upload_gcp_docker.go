@@ -15,6 +15,7 @@ import (
 	"go.uber.org/zap"
 
 	crauthn "github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
 	crremote "github.com/google/go-containerregistry/pkg/v1/remote"
 	crtransport "github.com/google/go-containerregistry/pkg/v1/remote/transport"
 )
@@ -24,10 +25,11 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryDocker(
 	j job.UploadableContainer,
 	zname string,
 	dst *config.Destination,
+	mounts map[string]crname.Reference,
 ) error {
 	l := logutils.LoggerFromContext(ctx)
 
-	if j.IsTagless() {
+	if j.IsTagless() && !dst.SyncsTagless() {
 		l.Info("Image is tag-less, skipping...")
 		return nil
 	}
@@ -52,6 +54,15 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryDocker(
 		l.Warn("There were issues while preparing image for upload", zap.Error(err))
 	}
 
+	if j.IsTagless() && dst.Tagless == config.DestinationTaglessDigest {
+		_ref, err := s.dockerDigestReference(ref, image, index)
+		if err != nil {
+			l.Error("Failed to compute digest reference for tag-less image", zap.Error(err))
+			return err
+		}
+		ref = _ref
+	}
+
 	var auth crauthn.Authenticator
 	{ // get authentication token
 		token, err := utils.WithTimeout(ctx, 10*time.Minute, func(ctx context.Context) (string, error) {
@@ -71,16 +82,26 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryDocker(
 		zap.String("destination_reference", ref.String()),
 	)
 
+	mountFrom := mounts[ref.Context().RegistryStr()]
+
+	recorder := newDockerBlobRecorder()
+
 	l.Debug("Pushing container to the destination")
 
 	var uploadErr error
 	{ // push
 		switch {
+		case image != nil && mountFrom != nil:
+			uploadErr = crremote.Write(ref, &dockerMountableImage{Image: image, reference: mountFrom}, crremote.WithAuth(auth), crremote.WithTransport(recorder))
+
 		case image != nil:
-			uploadErr = crremote.Write(ref, image, crremote.WithAuth(auth))
+			uploadErr = crremote.Write(ref, image, crremote.WithAuth(auth), crremote.WithTransport(recorder))
+
+		case index != nil && mountFrom != nil:
+			uploadErr = crremote.WriteIndex(ref, &dockerMountableIndex{index: index, reference: mountFrom}, crremote.WithAuth(auth), crremote.WithTransport(recorder))
 
 		case index != nil:
-			uploadErr = crremote.WriteIndex(ref, index, crremote.WithAuth(auth))
+			uploadErr = crremote.WriteIndex(ref, index, crremote.WithAuth(auth), crremote.WithTransport(recorder))
 		}
 	}
 
@@ -95,8 +116,16 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryDocker(
 		return uploadErr
 	}
 
+	mounts[ref.Context().RegistryStr()] = ref
+
+	if stats, err := recorder.stats(image, index); err == nil {
+		stats.report(ctx, l, ref)
+	} else {
+		l.Warn("Failed to summarise container blobs pushed to the destination", zap.Error(err))
+	}
+
 	{ // tag images referred by the index at the destination
-		if index != nil {
+		if _, isTag := ref.(crname.Tag); isTag && index != nil {
 			if err := s.dockerTagRemoteSubImages(ctx, ref, auth); err != nil {
 				l.Warn("Failed to tag sub-images of the container index", zap.Error(err))
 			}
@@ -105,5 +134,13 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryDocker(
 
 	l.Info("Pushed container image to the destination")
 
+	{ // apply the rest of the tags
+		if _, isTag := ref.(crname.Tag); isTag && len(j.GetTags()) > 1 {
+			if err := s.dockerTagRemote(ctx, ref, j.GetTags()[1:], image, index, auth); err != nil {
+				l.Warn("Failed to apply extra tags to the container image", zap.Error(err))
+			}
+		}
+	}
+
 	return nil
 }
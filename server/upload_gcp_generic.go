@@ -47,6 +47,11 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryGeneric(
 		files = _files
 	}
 
+	version := dst.Version
+	if version == "" {
+		version = j.GetVersion()
+	}
+
 	z, err := zip.OpenReader(zname)
 	if err != nil {
 		return fmt.Errorf("failed to open zip file: %w", err)
@@ -54,19 +59,41 @@ func (s *Server) uploadFromZipToGcpArtifactRegistryGeneric(
 	defer z.Close()
 
 	errs := make([]error, 0)
+	matched := 0
 iteratingFiles:
 	for _, f := range z.File {
 		if f.FileInfo().IsDir() {
 			continue iteratingFiles
 		}
 
-		l = l.With(
+		if !dst.IncludesFile(f.Name) {
+			l.Debug("Skipping file b/c it's filtered out by destination include/exclude rules",
+				zap.String("file", f.Name),
+			)
+			continue iteratingFiles
+		}
+		matched++
+
+		data := j.GetTemplateData()
+		data.Filename = f.Name
+		filename, err := dst.RenderFilename(data)
+		if err != nil {
+			errs = append(errs, utils.DoNotRetry(err))
+			l.Error("Failed to render the filename",
+				zap.Error(err),
+				zap.String("file", f.Name),
+			)
+			continue iteratingFiles
+		}
+
+		l := l.With(
 			zap.String("file", f.Name),
+			zap.String("filename", filename),
 		)
 
 		{ // check if the file already exists
 			filter := fmt.Sprintf(`name="%s/files/%s:%s:%s"`,
-				dst.Path, dst.Package, j.GetVersion(), f.Name,
+				dst.Path, dst.Package, version, filename,
 			)
 			res, err := files.List(dst.Path).Filter(filter).Do()
 			if err == nil && res.HTTPStatusCode != http.StatusOK {
@@ -148,9 +175,9 @@ iteratingFiles:
 			defer stream.Close()
 
 			req := artifacts.Upload(dst.Path, &artifactregistry.UploadGenericArtifactRequest{
-				Filename:  f.Name,
+				Filename:  filename,
 				PackageId: dst.Package,
-				VersionId: j.GetVersion(),
+				VersionId: version,
 			})
 			req.Media(stream, googleapi.ContentType("application/octet-stream"))
 
@@ -182,5 +209,9 @@ iteratingFiles:
 		}
 	}
 
+	if matched == 0 {
+		l.Warn("No files in the archive match destination include/exclude rules")
+	}
+
 	return utils.FlattenErrors(errs)
 }
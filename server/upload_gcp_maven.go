@@ -0,0 +1,129 @@
+package server
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+
+	"go.uber.org/zap"
+)
+
+func (s *Server) uploadFromZipToGcpArtifactRegistryMaven(
+	ctx context.Context,
+	_ job.Uploadable,
+	zname string,
+	dst *config.Destination,
+) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	var token string
+	{ // get authentication token
+		_token, err := s.gcp.AccessToken(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			l.Error("Failed to get gcp token", zap.Error(err))
+			return err
+		}
+		token = _token
+	}
+
+	z, err := zip.OpenReader(zname)
+	if err != nil {
+		return fmt.Errorf("failed to open zip file: %w", err)
+	}
+	defer z.Close()
+
+	errs := make([]error, 0)
+	for _, f := range z.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		l := l.With(
+			zap.String("file", f.Name),
+		)
+
+		_url := "https://" + dst.Path + "/" + f.Name
+
+		{ // check if the file already exists
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, _url, nil)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			req.SetBasicAuth("oauth2accesstoken", token)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				l.Error("Failed to check if the file exists in gcp artifact registry", zap.Error(err))
+				errs = append(errs, err)
+				continue
+			}
+			res.Body.Close()
+
+			if res.StatusCode == http.StatusOK {
+				l.Info("Maven file is already uploaded, skipping...")
+				continue
+			}
+		}
+
+		{ // upload
+			stream, err := f.Open()
+			if err != nil {
+				l.Error("Failed to extract maven file from the zip file", zap.Error(err))
+				errs = append(errs, err)
+				continue
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, _url, stream)
+			if err != nil {
+				stream.Close()
+				errs = append(errs, err)
+				continue
+			}
+			req.ContentLength = int64(f.UncompressedSize64)
+			req.SetBasicAuth("oauth2accesstoken", token)
+
+			start := time.Now()
+
+			l.Debug("Uploading maven file to gcp artifact registry...",
+				zap.Int64("size", f.FileInfo().Size()),
+			)
+
+			res, err := http.DefaultClient.Do(req)
+			stream.Close()
+			if err == nil {
+				res.Body.Close()
+				switch res.StatusCode {
+				case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+					// no-op
+				case http.StatusBadRequest, http.StatusForbidden, http.StatusConflict:
+					err = utils.DoNotRetry(fmt.Errorf("unexpected http status: %d", res.StatusCode))
+				default:
+					err = fmt.Errorf("unexpected http status: %d", res.StatusCode)
+				}
+			}
+			if err != nil {
+				l.Error("Failed to upload maven file to gcp artifact registry", zap.Error(err))
+				errs = append(errs, err)
+				continue
+			}
+
+			l.Info("Uploaded maven file into gcp artifact registry",
+				zap.Duration("duration", time.Since(start)),
+				zap.Int64("size", f.FileInfo().Size()),
+			)
+		}
+	}
+
+	return utils.FlattenErrors(errs)
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/flashbots/gh-artifacts-sync/job"
+	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/utils"
+
+	"go.uber.org/zap"
+)
+
+func (s *Server) uploadFromZipToGcpArtifactRegistryNpm(
+	ctx context.Context,
+	j job.UploadableNpmPackage,
+	zname string,
+	dst *config.Destination,
+) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	name := dst.Package
+	if name == "" {
+		name = j.GetNpmName()
+	}
+	packageUrl := "https://" + dst.Path + "/" + url.PathEscape(name)
+
+	l = l.With(
+		zap.String("npm_package", name),
+		zap.String("npm_version", j.GetVersion()),
+	)
+
+	var tarball []byte
+	{ // read the tarball
+		z, err := zip.OpenReader(zname)
+		if err != nil {
+			return fmt.Errorf("failed to open zip file: %w", err)
+		}
+		defer z.Close()
+
+		for _, f := range z.File {
+			if f.FileInfo().IsDir() || path.Ext(f.Name) != ".tgz" {
+				continue
+			}
+			stream, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("failed to extract npm tarball from the zip file: %w", err)
+			}
+			tarball, err = io.ReadAll(stream)
+			stream.Close()
+			if err != nil {
+				return fmt.Errorf("failed to read npm tarball from the zip file: %w", err)
+			}
+			break
+		}
+		if tarball == nil {
+			return utils.DoNotRetry(errors.New("no npm tarball in the zip file"))
+		}
+	}
+
+	var token string
+	{ // get authentication token
+		_token, err := s.gcp.AccessToken(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			l.Error("Failed to get gcp token", zap.Error(err))
+			return err
+		}
+		token = _token
+	}
+
+	var latest string
+	{ // check if the version already exists
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, packageUrl, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("authorization", "Bearer "+token)
+		req.Header.Set("accept", "application/json")
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to get npm package from gcp artifact registry: %w", err)
+		}
+		defer res.Body.Close()
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			var packument struct {
+				DistTags map[string]string          `json:"dist-tags"`
+				Versions map[string]json.RawMessage `json:"versions"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&packument); err != nil {
+				return fmt.Errorf("failed to decode npm package from gcp artifact registry: %w", err)
+			}
+			if _, exists := packument.Versions[j.GetVersion()]; exists {
+				l.Info("Npm package version is already uploaded, skipping...")
+				return nil
+			}
+			latest = packument.DistTags["latest"]
+		case http.StatusNotFound:
+			// no-op
+		default:
+			return fmt.Errorf("unexpected http status: %d", res.StatusCode)
+		}
+	}
+
+	var body []byte
+	{ // prepare the packument
+		manifest, err := npmTarballManifest(tarball)
+		if err != nil {
+			return utils.DoNotRetry(err)
+		}
+
+		version := j.GetVersion()
+		if latest == "" || npmVersionIsNewer(version, latest) {
+			latest = version
+		} else {
+			l.Info("Keeping npm package latest dist-tag b/c the version is not newer",
+				zap.String("npm_latest", latest),
+			)
+		}
+		attachment := fmt.Sprintf("%s-%s.tgz", path.Base(name), version)
+
+		sha1sum := sha1.Sum(tarball)
+		sha512sum := sha512.Sum512(tarball)
+
+		manifest["_id"] = name + "@" + version
+		manifest["name"] = name
+		manifest["version"] = version
+		manifest["dist"] = map[string]string{
+			"integrity": "sha512-" + base64.StdEncoding.EncodeToString(sha512sum[:]),
+			"shasum":    hex.EncodeToString(sha1sum[:]),
+			"tarball":   packageUrl + "/-/" + attachment,
+		}
+
+		body, err = json.Marshal(map[string]any{
+			"_id":       name,
+			"name":      name,
+			"dist-tags": map[string]string{"latest": latest},
+			"versions":  map[string]any{version: manifest},
+			"_attachments": map[string]any{
+				attachment: map[string]any{
+					"content_type": "application/octet-stream",
+					"data":         base64.StdEncoding.EncodeToString(tarball),
+					"length":       len(tarball),
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode npm packument: %w", err)
+		}
+	}
+
+	{ // upload
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, packageUrl, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("authorization", "Bearer "+token)
+		req.Header.Set("content-type", "application/json")
+
+		start := time.Now()
+
+		l.Debug("Uploading npm package to gcp artifact registry...",
+			zap.Int("size", len(tarball)),
+		)
+
+		res, err := http.DefaultClient.Do(req)
+		if err == nil {
+			res.Body.Close()
+			switch res.StatusCode {
+			case http.StatusOK, http.StatusCreated:
+				// no-op
+			case http.StatusBadRequest, http.StatusForbidden, http.StatusConflict:
+				err = utils.DoNotRetry(fmt.Errorf("unexpected http status: %d", res.StatusCode))
+			default:
+				err = fmt.Errorf("unexpected http status: %d", res.StatusCode)
+			}
+		}
+		if err != nil {
+			l.Error("Failed to upload npm package to gcp artifact registry", zap.Error(err))
+			return err
+		}
+
+		l.Info("Uploaded npm package into gcp artifact registry",
+			zap.Duration("duration", time.Since(start)),
+			zap.Int("size", len(tarball)),
+		)
+	}
+
+	return nil
+}
+
+// npmTarballManifest extracts package.json from npm package tarball.
+func npmTarballManifest(tarball []byte) (map[string]any, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress npm tarball: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read npm tarball: %w", err)
+		}
+
+		// npm packs everything under `package/` (but some tools don't)
+		parts := strings.SplitN(hdr.Name, "/", 2)
+		if len(parts) != 2 || parts[1] != "package.json" {
+			continue
+		}
+
+		manifest := make(map[string]any)
+		if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode package.json from npm tarball: %w", err)
+		}
+		return manifest, nil
+	}
+
+	return nil, errors.New("no package.json in npm tarball")
+}
+
+// npmVersionIsNewer returns true if the version takes precedence over the
+// other one according to the semver rules (build metadata is ignored).  The
+// versions that are not semver never take precedence.
+func npmVersionIsNewer(version, other string) bool {
+	v, ok := npmParseVersion(version)
+	if !ok {
+		return false
+	}
+	o, ok := npmParseVersion(other)
+	if !ok {
+		return true
+	}
+
+	for i := range 3 {
+		if v.core[i] != o.core[i] {
+			return v.core[i] > o.core[i]
+		}
+	}
+
+	switch {
+	case len(v.pre) == 0:
+		return len(o.pre) != 0
+	case len(o.pre) == 0:
+		return false
+	}
+
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		if v.pre[i] == o.pre[i] {
+			continue
+		}
+		vn, vErr := strconv.ParseUint(v.pre[i], 10, 64)
+		on, oErr := strconv.ParseUint(o.pre[i], 10, 64)
+		switch {
+		case vErr == nil && oErr == nil:
+			return vn > on
+		case vErr == nil:
+			return false // numeric identifiers have lower precedence
+		case oErr == nil:
+			return true
+		default:
+			return v.pre[i] > o.pre[i]
+		}
+	}
+	return len(v.pre) > len(o.pre)
+}
+
+type npmVersion struct {
+	core [3]uint64
+	pre  []string
+}
+
+func npmParseVersion(s string) (npmVersion, bool) {
+	var v npmVersion
+
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	if hasPre {
+		if pre == "" {
+			return v, false
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, false
+		}
+		v.core[i] = n
+	}
+
+	return v, true
+}
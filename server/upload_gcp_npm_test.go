@@ -0,0 +1,36 @@
+package server
+
+import "testing"
+
+func TestNpmVersionIsNewer(t *testing.T) {
+	tests := []struct {
+		version string
+		other   string
+		newer   bool
+	}{
+		{"1.2.4", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{"1.10.0", "1.9.0", true},
+		{"2.0.0", "1.99.99", true},
+		{"1.2.3", "1.2.3", false},
+		{"v1.2.4", "1.2.3", true},
+		{"1.2.3+build.2", "1.2.3+build.1", false},
+		{"1.2.3", "1.2.3-rc.1", true},
+		{"1.2.3-rc.1", "1.2.3", false},
+		{"1.2.3-rc.2", "1.2.3-rc.1", true},
+		{"1.2.3-rc.10", "1.2.3-rc.2", true},
+		{"1.2.3-rc.1", "1.2.3-beta.5", true},
+		{"1.2.3-alpha", "1.2.3-1", true},
+		{"1.2.3-alpha.1", "1.2.3-alpha", true},
+		{"1.2.4-rc.1", "1.2.3", true},
+		{"not-a-version", "1.2.3", false},
+		{"1.2.3", "not-a-version", true},
+		{"1.2", "1.1.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := npmVersionIsNewer(tt.version, tt.other); got != tt.newer {
+			t.Errorf("npmVersionIsNewer(%q, %q) = %v, want %v", tt.version, tt.other, got, tt.newer)
+		}
+	}
+}
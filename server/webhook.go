@@ -1,20 +1,55 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"io"
+	"mime"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/flashbots/gh-artifacts-sync/config"
 	"github.com/flashbots/gh-artifacts-sync/job"
 	"github.com/flashbots/gh-artifacts-sync/logutils"
+	"github.com/flashbots/gh-artifacts-sync/metrics"
 	"github.com/flashbots/gh-artifacts-sync/utils"
 	"github.com/google/go-github/v73/github"
+	"go.opentelemetry.io/otel/attribute"
+	otelapi "go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
+const (
+	ecosystemContainer = "CONTAINER"
+	ecosystemMaven     = "MAVEN"
+	ecosystemNpm       = "NPM"
+)
+
+var errWebhookNoSecrets = errors.New("no webhook secrets are configured")
+
+var supportedEcosystems = []string{
+	ecosystemContainer,
+	ecosystemMaven,
+	ecosystemNpm,
+}
+
+// supportedReleaseActions are the release event actions that (might) bring
+// the new assets to synchronise.  the jobs are keyed by asset id, so the same
+// asset being reported by several of them is not an issue.
+var supportedReleaseActions = []string{
+	"created",     // draft or release is created
+	"edited",      // release (e.g. its assets) is updated
+	"prereleased", // pre-release is published
+	"published",   // release or pre-release is published
+	"released",    // release is published or pre-release is turned into release
+}
+
 func (s *Server) webhook(w http.ResponseWriter, r *http.Request) {
 	l := logutils.LoggerFromRequest(r)
 
@@ -30,7 +65,7 @@ func (s *Server) webhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	payload, err := github.ValidatePayload(r, []byte(s.cfg.Github.WebhookSecret))
+	payload, err := s.validateWebhookPayload(r)
 	if err != nil {
 		l.Warn("Failed to validate webhook payload",
 			zap.Error(err),
@@ -39,6 +74,23 @@ func (s *Server) webhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	delivery := github.DeliveryID(r)
+	l = l.With(
+		zap.String("delivery", delivery),
+	)
+
+	if s.cfg.Dir.Deliveries != "" {
+		if fname, err := s.archiveDelivery(newDelivery(r, payload)); err == nil {
+			l.Debug("Archived webhook delivery",
+				zap.String("file", fname),
+			)
+		} else {
+			l.Warn("Failed to archive webhook delivery",
+				zap.Error(err),
+			)
+		}
+	}
+
 	event, err := github.ParseWebHook(github.WebHookType(r), payload)
 	if err != nil {
 		l.Warn("Failed to parse webhook event",
@@ -48,27 +100,114 @@ func (s *Server) webhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.deliveries.Seen(delivery) {
+		l.Info("Ignoring webhook delivery b/c it was already processed")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := s.processEvent(logutils.ContextWithLogger(r.Context(), l), event); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// failed deliveries are not recorded, so that github could redeliver them
+	if err := s.deliveries.Record(delivery); err != nil {
+		l.Warn("Failed to record webhook delivery",
+			zap.Error(err),
+		)
+	}
+}
+
+// validateWebhookPayload validates the payload with every accepted secret
+// (so that the secret could be rotated without rejecting the deliveries), and
+// records which one of them matched.
+func (s *Server) validateWebhookPayload(r *http.Request) ([]byte, error) {
+	l := logutils.LoggerFromRequest(r)
+
+	signature := r.Header.Get(github.SHA256SignatureHeader)
+	if signature == "" {
+		signature = r.Header.Get(github.SHA1SignatureHeader)
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = errWebhookNoSecrets
+	for idx, secret := range s.cfg.Github.AcceptedWebhookSecrets() {
+		payload, _err := github.ValidatePayloadFromBody(contentType, bytes.NewReader(body), signature, []byte(secret))
+		if _err != nil {
+			err = _err
+			continue
+		}
+
+		fingerprint := webhookSecretFingerprint(secret)
+		if idx > 0 {
+			l.Debug("Validated webhook payload with non-primary secret",
+				zap.Int("secret_index", idx),
+				zap.String("secret_fingerprint", fingerprint),
+			)
+		}
+		metrics.WebhookDeliveries.Add(r.Context(), 1, otelapi.WithAttributes(
+			attribute.String("outcome", "valid"),
+			attribute.Int("secret_index", idx),
+			attribute.Bool("secret_primary", idx == 0),
+			attribute.String("secret_fingerprint", fingerprint),
+		))
+
+		return payload, nil
+	}
+
+	metrics.WebhookDeliveries.Add(r.Context(), 1, otelapi.WithAttributes(
+		attribute.String("outcome", "invalid"),
+	))
+
+	return nil, err
+}
+
+// webhookSecretFingerprint identifies the secret (in the metrics and logs)
+// without disclosing it.
+func webhookSecretFingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:4])
+}
+
+// processEvent creates the jobs for the (already validated and parsed)
+// webhook event.
+func (s *Server) processEvent(ctx context.Context, event interface{}) error {
+	l := logutils.LoggerFromContext(ctx)
+
 	l.Debug("Received webhook event",
 		zap.String("event_type", reflect.TypeOf(event).String()),
 		zap.Any("event", event),
 	)
 
+	var err error
+
 	switch e := event.(type) {
 	default:
 		l.Info("Ignoring unsupported event",
 			zap.String("event_type", reflect.TypeOf(event).String()),
 		)
-		w.WriteHeader(http.StatusOK)
-		return
 
 	case *github.RegistryPackageEvent:
-		err = s.webhookProcessRegistryPackageEvent(r.Context(), e)
+		s.rememberInstallation(e.GetRepository().GetFullName(), e.GetInstallation().GetID())
+		err = s.webhookProcessRegistryPackageEvent(ctx, e)
 
 	case *github.ReleaseEvent:
-		err = s.webhookProcessReleaseEvent(r.Context(), e)
+		s.rememberInstallation(e.GetRepo().GetFullName(), e.GetInstallation().GetID())
+		err = s.webhookProcessReleaseEvent(ctx, e)
 
 	case *github.WorkflowRunEvent:
-		err = s.webhookProcessWorkflowEvent(r.Context(), e)
+		s.rememberInstallation(e.GetRepo().GetFullName(), e.GetInstallation().GetID())
+		err = s.webhookProcessWorkflowEvent(ctx, e)
 
 	}
 
@@ -77,9 +216,9 @@ func (s *Server) webhook(w http.ResponseWriter, r *http.Request) {
 			zap.Error(err),
 			zap.Any("event", event),
 		)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
 	}
+
+	return err
 }
 
 func (s *Server) webhookProcessRegistryPackageEvent(ctx context.Context, e *github.RegistryPackageEvent) error {
@@ -99,6 +238,10 @@ func (s *Server) webhookProcessRegistryPackageEvent(ctx context.Context, e *gith
 		zap.Int64("version_id", *e.RegistryPackage.PackageVersion.ID),
 	)
 
+	if *e.Action == "deleted" {
+		return s.webhookProcessRegistryPackageDeletion(ctx, e)
+	}
+
 	if *e.Action != "published" {
 		l.Debug("Ignoring registry package event b/c its status is not 'published'",
 			zap.String("action", *e.Action),
@@ -106,23 +249,61 @@ func (s *Server) webhookProcessRegistryPackageEvent(ctx context.Context, e *gith
 		return nil
 	}
 
-	if *e.RegistryPackage.Ecosystem != "CONTAINER" {
+	ecosystem := strings.ToUpper(*e.RegistryPackage.Ecosystem)
+	if !slices.Contains(supportedEcosystems, ecosystem) {
 		l.Debug("Ignoring registry package event b/c its ecosystem is not supported",
 			zap.String("ecosystem", *e.RegistryPackage.Ecosystem),
 		)
 		return nil
 	}
 
-	repo, repoIsConfigured := s.cfg.Repositories[must(e.Repository.FullName)]
+	repo, repoIsConfigured, err := s.repositoryAt(ctx, must(e.Repository.FullName), "")
+	if err != nil {
+		return err
+	}
 	if !repoIsConfigured {
 		l.Info("Ignoring registry package event b/c we don't have configuration for this repo")
 		return nil
 	}
 
-	container, containerIsConfigured := repo.Containers[*e.RegistryPackage.Name]
-	if !containerIsConfigured {
-		l.Info("Ignoring registry package event b/c we don't have configuration for this container")
-		return nil
+	var j job.Job
+
+	switch ecosystem {
+	case ecosystemContainer:
+		container, containerIsConfigured := repo.Containers[*e.RegistryPackage.Name]
+		if !containerIsConfigured {
+			l.Info("Ignoring registry package event b/c we don't have configuration for this container")
+			return nil
+		}
+		j = job.NewSyncContainerRegistryPackage(
+			e.RegistryPackage,
+			e.Repository,
+			container.Destinations,
+		)
+
+	case ecosystemMaven:
+		maven, mavenIsConfigured := repo.Maven[*e.RegistryPackage.Name]
+		if !mavenIsConfigured {
+			l.Info("Ignoring registry package event b/c we don't have configuration for this maven package")
+			return nil
+		}
+		j = job.NewSyncMavenPackage(
+			e.RegistryPackage,
+			e.Repository,
+			maven.Destinations,
+		)
+
+	case ecosystemNpm:
+		npm, npmIsConfigured := repo.Npm[*e.RegistryPackage.Name]
+		if !npmIsConfigured {
+			l.Info("Ignoring registry package event b/c we don't have configuration for this npm package")
+			return nil
+		}
+		j = job.NewSyncNpmPackage(
+			e.RegistryPackage,
+			e.Repository,
+			npm.Destinations,
+		)
 	}
 
 	if e.Repository.PushedAt != nil && time.Since(e.Repository.PushedAt.Time) > time.Minute {
@@ -131,12 +312,6 @@ func (s *Server) webhookProcessRegistryPackageEvent(ctx context.Context, e *gith
 		)
 	}
 
-	j := job.NewSyncContainerRegistryPackage(
-		e.RegistryPackage,
-		e.Repository,
-		container.Destinations,
-	)
-
 	fname, err := job.Save(j, s.cfg.Dir.Jobs)
 	if err != nil {
 		l.Error("Failed to persist a job",
@@ -166,22 +341,30 @@ func (s *Server) webhookProcessReleaseEvent(ctx context.Context, e *github.Relea
 		zap.String("repo", *e.Repo.FullName),
 		zap.String("release", *e.Release.Name),
 		zap.Int64("release_id", *e.Release.ID),
+		zap.String("action", *e.Action),
+		zap.Bool("draft", *e.Release.Draft),
+		zap.Bool("prerelease", *e.Release.Prerelease),
 	)
 
-	if *e.Action != "published" {
-		l.Debug("Ignoring release event b/c its status is not 'published'",
-			zap.String("action", *e.Action),
-		)
+	if *e.Action == "deleted" {
+		return s.webhookProcessReleaseDeletion(ctx, e)
+	}
+
+	if !slices.Contains(supportedReleaseActions, *e.Action) {
+		l.Debug("Ignoring release event b/c its action is not supported")
 		return nil
 	}
 
-	repo, repoIsConfigured := s.cfg.Repositories[must(e.Repo.FullName)]
+	repo, repoIsConfigured, err := s.repositoryAt(ctx, must(e.Repo.FullName), e.Release.GetTagName())
+	if err != nil {
+		return err
+	}
 	if !repoIsConfigured {
 		l.Info("Ignoring release event b/c we don't have configuration for this repo")
 		return nil
 	}
 
-	if e.Release.PublishedAt != nil && time.Since(e.Release.PublishedAt.Time) > time.Minute {
+	if *e.Action == "published" && e.Release.PublishedAt != nil && time.Since(e.Release.PublishedAt.Time) > time.Minute {
 		l.Warn("Github event was delivered late",
 			zap.Duration("delay", time.Since(e.Release.PublishedAt.Time)),
 		)
@@ -191,62 +374,73 @@ func (s *Server) webhookProcessReleaseEvent(ctx context.Context, e *github.Relea
 
 	jobsCount := 0
 
-	for _, cfgRelease := range repo.Releases {
-		cfgReleaseMatches := cfgRelease.Regexp().FindStringSubmatch(*e.Release.Name)
-		if len(cfgReleaseMatches) == 0 {
+	releaseData := &config.TemplateData{
+		ReleaseName: *e.Release.Name,
+		ReleaseTag:  e.Release.GetTagName(),
+		Repo:        e.Repo.GetName(),
+		RepoOwner:   e.Repo.GetOwner().GetLogin(),
+	}
+
+	for _, ghAsset := range e.Release.Assets {
+		matches, err := repo.MatchReleaseAsset(releaseData, *ghAsset.Name)
+		if err != nil {
+			l.Error("Failed to extract asset version",
+				zap.String("asset", *ghAsset.Name),
+				zap.Error(err),
+			)
+		}
+		matches = slices.DeleteFunc(matches, func(m *config.AssetMatch) bool {
+			if m.Release.Accepts(*e.Release.Draft, *e.Release.Prerelease) {
+				return false
+			}
+			l.Debug("Ignoring asset b/c release config does not accept drafts or pre-releases",
+				zap.String("asset", *ghAsset.Name),
+				zap.String("release_regexp", m.ReleaseRegexp),
+			)
+			return true
+		})
+		if len(matches) == 0 {
 			continue
 		}
 
-		releaseVersion := cfgReleaseMatches[0]
-		if len(cfgReleaseMatches) > 1 {
-			releaseVersion = cfgReleaseMatches[1]
+		if *ghAsset.State != "uploaded" {
+			l.Warn("Ignoring asset b/c its state is not 'uploaded'",
+				zap.String("asset", *ghAsset.Name),
+				zap.String("state", *ghAsset.State),
+			)
+			continue
+		}
+
+		if *ghAsset.ContentType != "application/zip" {
+			l.Warn("Ignoring asset b/c it's not a zip archive",
+				zap.String("asset", *ghAsset.Name),
+				zap.String("content_type", *ghAsset.ContentType),
+			)
+			continue
 		}
 
-		for _, cfgAsset := range cfgRelease.Assets {
-			for _, ghAsset := range e.Release.Assets {
-				cfgAssetMatches := cfgAsset.Regexp().FindStringSubmatch(*ghAsset.Name)
-				if len(cfgAssetMatches) == 0 {
-					continue
-				}
-
-				version := releaseVersion
-				if len(cfgAssetMatches) > 1 {
-					version = cfgAssetMatches[1]
-				}
-
-				if *ghAsset.State != "uploaded" {
-					l.Warn("Ignoring asset b/c its state is not 'uploaded'",
-						zap.String("asset", *ghAsset.Name),
-						zap.String("state", *ghAsset.State),
-					)
-					continue
-				}
-
-				if *ghAsset.ContentType != "application/zip" {
-					l.Warn("Ignoring asset b/c it's not a zip archive",
-						zap.String("asset", *ghAsset.Name),
-						zap.String("content_type", *ghAsset.ContentType),
-					)
-					continue
-				}
-
-				j := job.NewSyncReleaseAsset(
-					ghAsset,
-					version,
-					cfgAsset.Destinations,
+		for _, m := range matches {
+			j := job.NewSyncReleaseAsset(
+				ghAsset,
+				m.Data.Version,
+				m.Asset.Destinations,
+				&config.TemplateData{
+					Groups:      m.Data.Groups,
+					ReleaseName: m.Data.ReleaseName,
+					ReleaseTag:  m.Data.ReleaseTag,
+				},
+			)
+			jobsCount++
+
+			if fname, err := job.Save(j, s.cfg.Dir.Jobs); err == nil {
+				l.Info("Persisted a job",
+					zap.String("job", fname),
+				)
+			} else {
+				l.Error("Failed to persist a job",
+					zap.Error(err),
 				)
-				jobsCount++
-
-				if fname, err := job.Save(j, s.cfg.Dir.Jobs); err == nil {
-					l.Info("Persisted a job",
-						zap.String("job", fname),
-					)
-				} else {
-					l.Error("Failed to persist a job",
-						zap.Error(err),
-					)
-					errs = append(errs, err)
-				}
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -289,7 +483,12 @@ func (s *Server) webhookProcessWorkflowEvent(ctx context.Context, e *github.Work
 		return nil
 	}
 
-	repo, repoIsConfigured := s.cfg.Repositories[must(e.Repo.FullName)]
+	// per-repository config is read from the default branch (and not at the
+	// head commit, which could be authored by anyone in case of a fork)
+	repo, repoIsConfigured, err := s.repositoryAt(ctx, must(e.Repo.FullName), "")
+	if err != nil {
+		return err
+	}
 	if !repoIsConfigured {
 		l.Info("Ignoring workflow event b/c we don't have configuration for this repo")
 		return nil
@@ -302,9 +501,12 @@ func (s *Server) webhookProcessWorkflowEvent(ctx context.Context, e *github.Work
 		return nil
 	}
 
-	if len(workflow.Actors) > 0 && !workflow.HasActor(*e.WorkflowRun.TriggeringActor.Login) {
-		l.Info("Ignoring workflow event b/c triggering actor is not in the configured list",
+	if reason := workflowRunSkipReason(workflow, e.Repo, e.WorkflowRun); reason != "" {
+		l.Info("Ignoring workflow event b/c "+reason,
 			zap.String("actor", *e.WorkflowRun.TriggeringActor.Login),
+			zap.String("head_branch", e.WorkflowRun.GetHeadBranch()),
+			zap.String("head_repo", e.WorkflowRun.GetHeadRepository().GetFullName()),
+			zap.String("event", e.WorkflowRun.GetEvent()),
 		)
 		return nil
 	}
@@ -328,3 +530,161 @@ func (s *Server) webhookProcessWorkflowEvent(ctx context.Context, e *github.Work
 
 	return nil
 }
+
+func (s *Server) webhookProcessRegistryPackageDeletion(ctx context.Context, e *github.RegistryPackageEvent) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	if strings.ToUpper(*e.RegistryPackage.Ecosystem) != ecosystemContainer {
+		l.Debug("Ignoring registry package deletion b/c only container deletions are supported",
+			zap.String("ecosystem", *e.RegistryPackage.Ecosystem),
+		)
+		return nil
+	}
+
+	repo, repoIsConfigured, err := s.repositoryAt(ctx, must(e.Repository.FullName), "")
+	if err != nil {
+		return err
+	}
+	if !repoIsConfigured {
+		l.Info("Ignoring registry package deletion b/c we don't have configuration for this repo")
+		return nil
+	}
+
+	container, containerIsConfigured := repo.Containers[*e.RegistryPackage.Name]
+	if !containerIsConfigured {
+		l.Info("Ignoring registry package deletion b/c we don't have configuration for this container")
+		return nil
+	}
+	if container.Deletion == nil {
+		l.Info("Ignoring registry package deletion b/c deletions are not enabled for this container")
+		return nil
+	}
+
+	tags := make([]string, 0, 1)
+	if tag := *e.RegistryPackage.PackageVersion.ContainerMetadata.Tag.Name; tag != "" {
+		switch {
+		case !container.HasTag(tag):
+			l.Debug("Ignoring tag of the deleted container b/c it does not match the configuration",
+				zap.String("tag", tag),
+			)
+		case container.Deletion.IsProtected(tag):
+			l.Info("Ignoring tag of the deleted container b/c it is protected",
+				zap.String("tag", tag),
+			)
+		default:
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		l.Info("Ignoring registry package deletion b/c there are no tags to delete")
+		return nil
+	}
+
+	j := job.NewDeletePackageVersion(
+		e.RegistryPackage,
+		e.Repository,
+		tags,
+		container,
+		container.Destinations,
+		time.Now().Add(container.Deletion.GracePeriod),
+	)
+
+	fname, err := job.Save(j, s.cfg.Dir.Jobs)
+	if err != nil {
+		l.Error("Failed to persist a job",
+			zap.Error(err),
+		)
+		return err
+	}
+
+	l.Info("Persisted a job",
+		zap.String("job", fname),
+		zap.Strings("tags", tags),
+		zap.Time("not_before", j.NotBefore),
+	)
+
+	return nil
+}
+
+func (s *Server) webhookProcessReleaseDeletion(ctx context.Context, e *github.ReleaseEvent) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	repo, repoIsConfigured, err := s.repositoryAt(ctx, must(e.Repo.FullName), "")
+	if err != nil {
+		return err
+	}
+	if !repoIsConfigured {
+		l.Info("Ignoring release deletion b/c we don't have configuration for this repo")
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	jobsCount := 0
+
+	releaseData := &config.TemplateData{
+		ReleaseName: *e.Release.Name,
+		ReleaseTag:  e.Release.GetTagName(),
+		Repo:        e.Repo.GetName(),
+		RepoOwner:   e.Repo.GetOwner().GetLogin(),
+	}
+
+	for _, ghAsset := range e.Release.Assets {
+		matches, err := repo.MatchReleaseAsset(releaseData, *ghAsset.Name)
+		if err != nil {
+			l.Error("Failed to extract asset version",
+				zap.String("asset", *ghAsset.Name),
+				zap.Error(err),
+			)
+		}
+
+		for _, m := range matches {
+			if m.Release.Deletion == nil {
+				continue
+			}
+
+			if m.Release.Deletion.IsProtected(m.Data.Version) ||
+				m.Release.Deletion.IsProtected(m.Data.ReleaseTag) {
+				// ---
+				l.Info("Ignoring asset of the deleted release b/c its version is protected",
+					zap.String("asset", *ghAsset.Name),
+					zap.String("version", m.Data.Version),
+				)
+				continue
+			}
+
+			j := job.NewDeleteReleaseAsset(
+				e.Release,
+				e.Repo,
+				ghAsset,
+				m.Data.Version,
+				m.Asset.Destinations,
+				&config.TemplateData{
+					Groups:      m.Data.Groups,
+					ReleaseName: m.Data.ReleaseName,
+					ReleaseTag:  m.Data.ReleaseTag,
+				},
+				time.Now().Add(m.Release.Deletion.GracePeriod),
+			)
+			jobsCount++
+
+			if fname, err := job.Save(j, s.cfg.Dir.Jobs); err == nil {
+				l.Info("Persisted a job",
+					zap.String("job", fname),
+					zap.Time("not_before", j.NotBefore),
+				)
+			} else {
+				l.Error("Failed to persist a job",
+					zap.Error(err),
+				)
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if jobsCount == 0 {
+		l.Info("Ignoring release deletion b/c we don't have release/asset matches with deletions enabled")
+	}
+
+	return utils.FlattenErrors(errs)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flashbots/gh-artifacts-sync/config"
+	"github.com/google/go-github/v73/github"
+)
+
+func TestValidateWebhookPayload(t *testing.T) {
+	body := []byte(`{"action":"published"}`)
+
+	sign := func(h func() hash.Hash, prefix, secret string) string {
+		mac := hmac.New(h, []byte(secret))
+		mac.Write(body)
+		return prefix + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		secrets   []string
+		header    string
+		signature string
+		valid     bool
+	}{
+		{
+			name:      "primary secret",
+			secret:    "primary",
+			header:    github.SHA256SignatureHeader,
+			signature: sign(sha256.New, "sha256=", "primary"),
+			valid:     true,
+		},
+		{
+			name:      "rotated secret",
+			secret:    "primary",
+			secrets:   []string{"old", "older"},
+			header:    github.SHA256SignatureHeader,
+			signature: sign(sha256.New, "sha256=", "older"),
+			valid:     true,
+		},
+		{
+			name:      "secrets list only",
+			secrets:   []string{"old"},
+			header:    github.SHA256SignatureHeader,
+			signature: sign(sha256.New, "sha256=", "old"),
+			valid:     true,
+		},
+		{
+			name:      "sha1 signature",
+			secret:    "primary",
+			header:    github.SHA1SignatureHeader,
+			signature: sign(sha1.New, "sha1=", "primary"),
+			valid:     true,
+		},
+		{
+			name:      "unknown secret",
+			secret:    "primary",
+			secrets:   []string{"old"},
+			header:    github.SHA256SignatureHeader,
+			signature: sign(sha256.New, "sha256=", "unknown"),
+		},
+		{
+			name:   "no signature",
+			secret: "primary",
+		},
+		{
+			name:      "no secrets",
+			header:    github.SHA256SignatureHeader,
+			signature: sign(sha256.New, "sha256=", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.New()
+			cfg.Github.WebhookSecret = tt.secret
+			cfg.Github.WebhookSecrets = tt.secrets
+
+			s := &Server{cfg: cfg}
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.signature)
+			}
+
+			payload, err := s.validateWebhookPayload(r)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("validateWebhookPayload() error = %v", err)
+				}
+				if !bytes.Equal(payload, body) {
+					t.Errorf("validateWebhookPayload() = %s, want %s", payload, body)
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("validateWebhookPayload() = %s, want an error", payload)
+			}
+		})
+	}
+}